@@ -0,0 +1,15 @@
+package main
+
+import (
+	"os"
+
+	"github.com/alex-held/devctl-kit/pkg/cli/cmds/banner"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+func main() {
+	cmd := banner.NewCmd(env.NewFactory())
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/alex-held/devctl-kit/pkg/cli/cmds/profile"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+func main() {
+	flags := pflag.NewFlagSet("profile", pflag.ContinueOnError)
+	env.AddProfileFlag(flags)
+
+	cmd := profile.NewCmd(env.NewFactory(env.WithProfileFlags(flags)))
+	cmd.PersistentFlags().AddFlagSet(flags)
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+
+	storecmd "github.com/alex-held/devctl-kit/pkg/cli/cmds/store"
+	"github.com/alex-held/devctl-kit/pkg/config"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+func main() {
+	flags := pflag.NewFlagSet("store", pflag.ContinueOnError)
+	config.AddFlags(flags)
+	env.AddProfileFlag(flags)
+
+	cmd := storecmd.NewCmd(env.NewFactory(env.WithConfigOptions(config.WithFlags(flags)), env.WithProfileFlags(flags)))
+	cmd.PersistentFlags().AddFlagSet(flags)
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -5,7 +5,6 @@ go 1.16
 require (
 	github.com/alex-held/devctl v0.10.1
 	github.com/alex-held/gold v1.0.2
-	github.com/common-nighthawk/go-figure v0.0.0-20210622060536-734e95fb86be
 	github.com/exponent-io/jsonpath v0.0.0-20210407135951-1de76d718b3f
 	github.com/franela/goblin v0.0.0-20210519012713-85d372ac71e2
 	github.com/onsi/gomega v1.16.0
@@ -13,6 +12,9 @@ require (
 	github.com/sebdah/goldie/v2 v2.5.3
 	github.com/spf13/afero v1.6.0
 	github.com/spf13/cobra v1.2.1
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/text v0.3.7
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 	k8s.io/apimachinery v0.22.2
 )
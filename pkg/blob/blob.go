@@ -0,0 +1,204 @@
+// Package blob stores downloaded artifacts by their sha256, so that they are
+// downloaded once for all versions and profiles.
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/log"
+)
+
+var (
+	// ErrChecksumMismatch is returned if content does not have the expected sha256
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrNotFound is returned if the store has no blob with a sum
+	ErrNotFound = errors.New("blob not found")
+)
+
+var sumRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidateSum returns an error if sum is no lower case, hex encoded sha256
+func ValidateSum(sum string) error {
+	if !sumRe.MatchString(sum) {
+		return errors.Errorf("invalid sha256 %q, expected 64 lower case hex digits", sum)
+	}
+	return nil
+}
+
+// Blob is a file of the store and its size in bytes. Sum is empty for temporary files.
+type Blob struct {
+	Sum  string
+	Path string
+	Size int64
+}
+
+// Store keeps blobs in Paths.BlobPath, at the path of their hex encoded sha256. Blobs
+// are written once and never modified, the installer extracts artifacts from them.
+type Store struct {
+	fs    afero.Fs
+	paths env.Paths
+	log   log.Logger
+	now   func() time.Time
+}
+
+type StoreOption func(*Store) *Store
+
+// WithClock sets the function the age of blobs is measured with
+func WithClock(now func() time.Time) StoreOption {
+	return func(s *Store) *Store {
+		s.now = now
+		return s
+	}
+}
+
+// NewStore returns a Store using the Fs, Paths and Logger of f
+func NewStore(f env.Factory, opts ...StoreOption) *Store {
+	s := &Store{
+		fs:    f.Fs(),
+		paths: f.Paths(),
+		log:   f.Logger(),
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Path returns the path of the blob sum
+func (s *Store) Path(sum string) string { return s.paths.Blob(sum) }
+
+// Open opens the blob sum or returns ErrNotFound
+func (s *Store) Open(sum string) (afero.File, error) {
+	if err := ValidateSum(sum); err != nil {
+		return nil, err
+	}
+	f, err := s.fs.Open(s.Path(sum))
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(ErrNotFound, "sha256 %s", sum)
+	}
+	return f, err
+}
+
+// Check returns whether the blob sum exists and is intact. A corrupt blob is removed,
+// so that it is stored again.
+func (s *Store) Check(sum string) (bool, error) {
+	f, err := s.Open(sum)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	actual, err := checksum(f)
+	f.Close()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read %s", s.Path(sum))
+	}
+	if actual == sum {
+		return true, nil
+	}
+	s.log.Warnf("removing the corrupt blob %s, its sha256 is %s", s.Path(sum), actual)
+	if err = s.fs.Remove(s.Path(sum)); err != nil {
+		return false, errors.Wrapf(err, "failed to remove %s", s.Path(sum))
+	}
+	return false, nil
+}
+
+// Put stores the content of r as the blob sum. The content is written to a temporary
+// file, which is moved into place if its sha256 is sum, so that a blob is either
+// missing or complete. It fails with ErrChecksumMismatch otherwise.
+func (s *Store) Put(sum string, r io.Reader) (err error) {
+	if err = ValidateSum(sum); err != nil {
+		return err
+	}
+	path := s.Path(sum)
+	if err = s.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create %s", filepath.Dir(path))
+	}
+	tmp, err := afero.TempFile(s.fs, filepath.Dir(path), "."+sum+".tmp-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary file")
+	}
+	defer func() {
+		if err != nil {
+			_ = s.fs.Remove(tmp.Name())
+		}
+	}()
+
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(tmp, h), r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != sum {
+		return errors.Wrapf(ErrChecksumMismatch, "sha256 is %s, expected %s", actual, sum)
+	}
+	return s.fs.Rename(tmp.Name(), path)
+}
+
+// List returns the blobs sorted by sum
+func (s *Store) List() ([]Blob, error) {
+	var blobs []Blob
+	err := s.walk(func(b Blob, _ os.FileInfo) error {
+		if b.Sum != "" {
+			blobs = append(blobs, b)
+		}
+		return nil
+	})
+	return blobs, err
+}
+
+// walk calls fn for the files in Paths.BlobPath, which are sorted by path. The Sum of
+// a file is its name if it is a valid sha256 in the right directory.
+func (s *Store) walk(fn func(Blob, os.FileInfo) error) error {
+	root := s.paths.BlobPath()
+	err := afero.Walk(s.fs, root, func(path string, fi os.FileInfo, err error) error {
+		if path == root && os.IsNotExist(err) {
+			return nil
+		} else if err != nil || fi.IsDir() {
+			return err
+		}
+		b := Blob{Path: path, Size: fi.Size()}
+		if name := fi.Name(); ValidateSum(name) == nil && s.Path(name) == path {
+			b.Sum = name
+		}
+		return fn(b, fi)
+	})
+	return errors.Wrapf(err, "failed to scan %s", root)
+}
+
+// FormatSize formats n bytes using binary prefixes, e.g. 1.5 MiB
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func checksum(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
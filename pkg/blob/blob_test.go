@@ -0,0 +1,151 @@
+package blob_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/blob"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+)
+
+func sum(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func put(t *testing.T, s *blob.Store, content string) string {
+	t.Helper()
+	require.NoError(t, s.Put(sum(content), strings.NewReader(content)))
+	return sum(content)
+}
+
+// writeReceipt records that paths installed an artifact with content
+func writeReceipt(t *testing.T, e *envtest.Env, paths env.Paths, version, content string) {
+	t.Helper()
+	r := receipt.New("go", version, envtest.DefaultRuntimeInfo)
+	r.Checksum = sum(content)
+	b, err := yaml.Marshal(r)
+	require.NoError(t, err)
+	require.NoError(t, env.WriteFileAtomic(e.Fs, paths.PluginVersionInstallReceiptPath("go", version), b, 0644))
+}
+
+func TestStore_Put(t *testing.T) {
+	e := envtest.New(t)
+	s := blob.NewStore(e.Factory)
+
+	sum := put(t, s, "artifact")
+	assert.Equal(t, filepath.Join(e.Paths.Base(), "store", ".blobs", "sha256", sum[:2], sum), s.Path(sum))
+	f, err := s.Open(sum)
+	require.NoError(t, err)
+	b, err := afero.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, "artifact", string(b))
+	assert.Equal(t, s.Path(sum), e.Paths.WithProfile("work").Blob(sum), "blobs are shared by the profiles")
+
+	err = s.Put(sum, strings.NewReader("other"))
+	assert.True(t, errors.Is(err, blob.ErrChecksumMismatch), "got %v", err)
+	infos, err := afero.ReadDir(e.Fs, filepath.Dir(s.Path(sum)))
+	require.NoError(t, err)
+	require.Len(t, infos, 1, "the temporary file is removed")
+
+	_, err = s.Open(strings.Repeat("0", 64))
+	assert.True(t, errors.Is(err, blob.ErrNotFound), "got %v", err)
+	assert.Error(t, s.Put("../evil", strings.NewReader("evil")))
+}
+
+func TestStore_Check(t *testing.T) {
+	e := envtest.New(t)
+	s := blob.NewStore(e.Factory)
+	sum := put(t, s, "artifact")
+
+	ok, err := s.Check(sum)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, afero.WriteFile(e.Fs, s.Path(sum), []byte("corrupt"), 0644))
+	ok, err = s.Check(sum)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	exists, err := afero.Exists(e.Fs, s.Path(sum))
+	require.NoError(t, err)
+	assert.False(t, exists, "the corrupt blob is removed")
+}
+
+func TestStore_Verify(t *testing.T) {
+	e := envtest.New(t)
+	s := blob.NewStore(e.Factory)
+	intact, corrupt := put(t, s, "intact"), put(t, s, "corrupt")
+	require.NoError(t, afero.WriteFile(e.Fs, s.Path(corrupt), []byte("modified"), 0644))
+	misplaced := filepath.Join(e.Paths.BlobPath(), "misplaced")
+	require.NoError(t, afero.WriteFile(e.Fs, misplaced, []byte("misplaced"), 0644))
+	require.NoError(t, afero.WriteFile(e.Fs, filepath.Join(e.Paths.BlobPath(), ".tmp-1"), nil, 0644))
+
+	report, err := s.Verify(blob.VerifyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []blob.Blob{{Sum: intact, Path: s.Path(intact), Size: 6}}, report.Blobs)
+	require.Len(t, report.Corrupt, 2)
+	assert.Equal(t, corrupt, report.Corrupt[0].Sum)
+	assert.Equal(t, misplaced, report.Corrupt[1].Path)
+	assert.Equal(t, "corrupt "+s.Path(corrupt)+"\ncorrupt "+misplaced+"\n3 blobs verified, 2 corrupt\n", report.String())
+
+	report, err = s.Verify(blob.VerifyOptions{Remove: true})
+	require.NoError(t, err)
+	assert.Contains(t, report.String(), "2 corrupt, removed\n")
+	report, err = s.Verify(blob.VerifyOptions{})
+	require.NoError(t, err)
+	assert.Len(t, report.Blobs, 1)
+	assert.Empty(t, report.Corrupt)
+}
+
+func TestStore_Prune(t *testing.T) {
+	e := envtest.New(t)
+	now := time.Now().Add(time.Hour)
+	s := blob.NewStore(e.Factory, blob.WithClock(func() time.Time { return now }))
+	work := e.Paths.WithProfile("work")
+	_, err := e.Paths.CreateProfile(e.Fs, "work")
+	require.NoError(t, err)
+
+	installed, shared, unused := put(t, s, "installed"), put(t, s, "shared"), put(t, s, "unused")
+	writeReceipt(t, e, e.Paths, "1.16", "installed")
+	writeReceipt(t, e, work, "1.17", "shared")
+	tmp := filepath.Join(e.Paths.BlobPath(), unused[:2], "."+unused+".tmp-1")
+	require.NoError(t, afero.WriteFile(e.Fs, tmp, []byte("partial"), 0644))
+	fresh := put(t, s, "fresh")
+	require.NoError(t, e.Fs.Chtimes(s.Path(fresh), now, now))
+
+	report, err := s.Prune(blob.PruneOptions{DryRun: true, MinAge: 30 * time.Minute})
+	require.NoError(t, err)
+	require.Len(t, report.Blobs, 2)
+	assert.Equal(t, tmp, report.Blobs[0].Path)
+	assert.Equal(t, blob.Blob{Sum: unused, Path: s.Path(unused), Size: 6}, report.Blobs[1])
+	assert.Equal(t, int64(13), report.Size())
+	assert.Contains(t, report.String(), "13 B reclaimable\n")
+
+	report, err = s.Prune(blob.PruneOptions{MinAge: 30 * time.Minute})
+	require.NoError(t, err)
+	assert.Len(t, report.Blobs, 2)
+	blobs, err := s.List()
+	require.NoError(t, err)
+	var sums []string
+	for _, b := range blobs {
+		sums = append(sums, b.Sum)
+	}
+	assert.ElementsMatch(t, []string{installed, shared, fresh}, sums)
+
+	require.NoError(t, afero.WriteFile(e.Fs, work.PluginVersionInstallReceiptPath("go", "1.18"), []byte("{"), 0644))
+	_, err = s.Prune(blob.PruneOptions{})
+	assert.Error(t, err, "an unreadable receipt aborts the prune")
+}
@@ -0,0 +1,132 @@
+package blob
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+)
+
+// PruneOptions configure Prune
+type PruneOptions struct {
+	// DryRun reports the blobs without removing them
+	DryRun bool
+	// MinAge is the age below which blobs are kept, as they may belong to an install
+	// in progress
+	MinAge time.Duration
+}
+
+// PruneReport lists the blobs removed by Prune, sorted by path
+type PruneReport struct {
+	Blobs  []Blob
+	DryRun bool
+}
+
+// Size returns the reclaimable bytes
+func (r *PruneReport) Size() (size int64) {
+	for _, b := range r.Blobs {
+		size += b.Size
+	}
+	return size
+}
+
+// String returns a line per blob followed by the total size
+func (r *PruneReport) String() string {
+	sb := &strings.Builder{}
+	for _, b := range r.Blobs {
+		fmt.Fprintf(sb, "%10s %s\n", FormatSize(b.Size), b.Path)
+	}
+	verb := "freed"
+	if r.DryRun {
+		verb = "reclaimable"
+	}
+	fmt.Fprintf(sb, "%s %s\n", FormatSize(r.Size()), verb)
+	return sb.String()
+}
+
+// Prune removes the blobs which are not the artifact of an install receipt of any
+// profile, and the temporary files of interrupted writes. This is a mark and sweep, so
+// a receipt which can not be parsed aborts it rather than losing the blob.
+func (s *Store) Prune(opts PruneOptions) (*PruneReport, error) {
+	live, err := s.referenced()
+	if err != nil {
+		return nil, err
+	}
+	report := &PruneReport{DryRun: opts.DryRun}
+	cutoff := s.now().Add(-opts.MinAge)
+	err = s.walk(func(b Blob, fi os.FileInfo) error {
+		switch {
+		case live[b.Sum], fi.ModTime().After(cutoff):
+		case b.Sum == "" && !strings.HasPrefix(fi.Name(), "."):
+			// misplaced files are reported by Verify
+		default:
+			report.Blobs = append(report.Blobs, b)
+		}
+		return nil
+	})
+	if err != nil || opts.DryRun {
+		return report, err
+	}
+	for _, b := range report.Blobs {
+		if err = s.fs.Remove(b.Path); err != nil {
+			return report, errors.Wrapf(err, "failed to remove %s", b.Path)
+		}
+	}
+	return report, nil
+}
+
+// referenced returns the checksums recorded by the install receipts of all profiles,
+// including the ones whose configuration was removed
+func (s *Store) referenced() (map[string]bool, error) {
+	profiles, err := s.paths.Profiles(s.fs)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(s.paths.Base(), constants.ProfilesDir)
+	infos, err := afero.ReadDir(s.fs, dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to list %s", dir)
+	}
+	for _, fi := range infos {
+		if fi.IsDir() && env.ValidateProfile(fi.Name()) == nil {
+			profiles = append(profiles, fi.Name())
+		}
+	}
+
+	live := map[string]bool{}
+	for _, profile := range profiles {
+		dir := s.paths.WithProfile(profile).InstallReceiptsPath()
+		err = afero.Walk(s.fs, dir, func(path string, fi os.FileInfo, err error) error {
+			if path == dir && os.IsNotExist(err) {
+				return nil
+			} else if err != nil || fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || filepath.Ext(path) != constants.ManifestExtension {
+				return err
+			}
+			b, err := afero.ReadFile(s.fs, path)
+			if err != nil {
+				return err
+			}
+			r := &receipt.Receipt{}
+			if err = yaml.Unmarshal(b, r); err != nil {
+				return errors.Wrapf(err, "failed to parse %s", path)
+			}
+			if r.Checksum != "" {
+				live[strings.ToLower(r.Checksum)] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the receipts of profile %s", profile)
+		}
+	}
+	return live, nil
+}
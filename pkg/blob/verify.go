@@ -0,0 +1,73 @@
+package blob
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VerifyOptions configure Verify
+type VerifyOptions struct {
+	// Remove deletes the corrupt blobs, so that they are downloaded again
+	Remove bool
+}
+
+// VerifyReport lists the blobs checked by Verify, sorted by path
+type VerifyReport struct {
+	Blobs   []Blob
+	Corrupt []Blob
+	Removed bool
+}
+
+// String returns a line per corrupt blob followed by the totals
+func (r *VerifyReport) String() string {
+	sb := &strings.Builder{}
+	for _, b := range r.Corrupt {
+		fmt.Fprintf(sb, "corrupt %s\n", b.Path)
+	}
+	fmt.Fprintf(sb, "%d blobs verified, %d corrupt", len(r.Blobs)+len(r.Corrupt), len(r.Corrupt))
+	if r.Removed && len(r.Corrupt) > 0 {
+		sb.WriteString(", removed")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// Verify hashes the blobs and reports the ones whose content does not match their sum,
+// including files which are not named by their sha256. Temporary files are skipped.
+func (s *Store) Verify(opts VerifyOptions) (*VerifyReport, error) {
+	report := &VerifyReport{Removed: opts.Remove}
+	err := s.walk(func(b Blob, fi os.FileInfo) error {
+		if strings.HasPrefix(fi.Name(), ".") {
+			return nil
+		}
+		if b.Sum != "" {
+			f, err := s.fs.Open(b.Path)
+			if err != nil {
+				return err
+			}
+			actual, err := checksum(f)
+			f.Close()
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %s", b.Path)
+			}
+			if actual == b.Sum {
+				report.Blobs = append(report.Blobs, b)
+				return nil
+			}
+		}
+		report.Corrupt = append(report.Corrupt, b)
+		return nil
+	})
+	if err != nil || !opts.Remove {
+		return report, err
+	}
+	for _, b := range report.Corrupt {
+		if err = s.fs.Remove(b.Path); err != nil {
+			return report, errors.Wrapf(err, "failed to remove %s", b.Path)
+		}
+	}
+	return report, nil
+}
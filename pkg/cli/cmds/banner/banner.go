@@ -0,0 +1,223 @@
+package banner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/alex-held/devctl-kit/pkg/cli"
+	"github.com/alex-held/devctl-kit/pkg/cli/util"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+// PreviewText is rendered by --preview if no text is given
+const PreviewText = "devctl"
+
+type BannerOptions struct {
+	cli.IOStreams
+
+	Text      string
+	Font      string
+	Kind      string
+	Frame     string
+	Layout    string
+	Width     int
+	Subtitle  string
+	Metadata  []string
+	InPlace   string
+	ListFonts bool
+	Preview   bool
+
+	kind   banner.OutputKind
+	opts   []banner.Option
+	fs     afero.Fs
+	byFile bool
+}
+
+// NewBannerOptions returns an initialized BannerOptions instance
+func NewBannerOptions(streams cli.IOStreams) *BannerOptions {
+	return &BannerOptions{
+		IOStreams: streams,
+		Font:      banner.DefaultFont,
+		Kind:      banner.KIND_SHELL.String(),
+		Frame:     banner.FrameDashes.String(),
+		Layout:    banner.LayoutFullWidth.String(),
+	}
+}
+
+// Complete completes all the required options
+func (o *BannerOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.fs = f.Fs()
+	if o.Text == "" {
+		o.Text = strings.Join(args, " ")
+	}
+	// the comment syntax of --in-place files is inferred unless --kind is given
+	o.byFile = o.InPlace != "" && !cmd.Flags().Changed("kind")
+	return nil
+}
+
+// ValidateArgs makes sure there is no discrepancy in command options
+func (o *BannerOptions) ValidateArgs(cmd *cobra.Command, args []string) (err error) {
+	if o.ListFonts {
+		return nil
+	}
+	if o.Text == "" && !o.Preview {
+		return util.UsageErrorf(cmd, "text is required")
+	}
+	if o.Width < 0 {
+		return util.UsageErrorf(cmd, "--width must not be negative")
+	}
+	if !o.Preview && !hasFont(o.Font) {
+		return util.UsageErrorf(cmd, "unknown font %q, see --list-fonts", o.Font)
+	}
+	if o.kind, err = banner.ParseKind(o.Kind); err != nil {
+		return util.UsageErrorf(cmd, "%v, expected one of %s", err, strings.Join(banner.Kinds(), ", "))
+	}
+	if o.byFile {
+		kind, ok := banner.KindForFile(o.InPlace)
+		if !ok {
+			return util.UsageErrorf(cmd, "cannot infer the comment syntax of %s, use --kind", o.InPlace)
+		}
+		o.kind = kind
+	}
+	frame, err := banner.ParseFrameStyle(o.Frame)
+	if err != nil {
+		return util.UsageErrorf(cmd, "%v, expected one of %s", err, strings.Join(banner.FrameStyles(), ", "))
+	}
+	// the banner is found again by its decoration lines
+	if o.InPlace != "" && frame == banner.FrameNone {
+		return util.UsageErrorf(cmd, "--in-place requires decoration lines, banners without a frame cannot be replaced")
+	}
+	layout, ok := banner.ParseLayout(o.Layout)
+	if !ok {
+		return util.UsageErrorf(cmd, "unknown layout %q", o.Layout)
+	}
+
+	o.opts = []banner.Option{
+		banner.WithFont(o.Font),
+		banner.WithFrame(frame),
+		banner.WithLayout(layout),
+		banner.WithWidth(o.Width),
+		banner.WithSubtitle(o.Subtitle),
+	}
+	for _, field := range o.Metadata {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return util.UsageErrorf(cmd, "invalid metadata %q, expected KEY=VALUE", field)
+		}
+		o.opts = append(o.opts, banner.WithMetadata(kv[0], kv[1]))
+	}
+	return nil
+}
+
+// Run prints, previews or writes the banner
+func (o *BannerOptions) Run() error {
+	switch {
+	case o.ListFonts:
+		for _, name := range banner.Fonts() {
+			fmt.Fprintln(o.Out, name)
+		}
+		return nil
+	case o.Preview:
+		return o.preview()
+	case o.InPlace != "":
+		return o.writeInPlace()
+	default:
+		_, err := fmt.Fprint(o.Out, banner.GenerateBanner(o.Text, o.kind, o.opts...))
+		return err
+	}
+}
+
+// preview renders the text in every embedded font
+func (o *BannerOptions) preview() error {
+	text := o.Text
+	if text == "" {
+		text = PreviewText
+	}
+	for _, name := range banner.Fonts() {
+		opts := append(append([]banner.Option{}, o.opts...), banner.WithFont(name))
+		if _, err := fmt.Fprintf(o.Out, "%s:\n%s\n", name, banner.GenerateBanner(text, o.kind, opts...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeInPlace replaces the banner at the top of the --in-place file
+func (o *BannerOptions) writeInPlace() error {
+	mode := os.FileMode(0644)
+	content := ""
+	if fi, err := o.fs.Stat(o.InPlace); err == nil {
+		mode = fi.Mode().Perm()
+		b, err := afero.ReadFile(o.fs, o.InPlace)
+		if err != nil {
+			return err
+		}
+		content = string(b)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	updated := banner.Replace(content, banner.GenerateBanner(o.Text, o.kind, o.opts...), o.kind)
+	if updated == content {
+		return nil
+	}
+	return env.WriteFileAtomic(o.fs, o.InPlace, []byte(updated), mode)
+}
+
+func hasFont(name string) bool {
+	for _, font := range banner.Fonts() {
+		if font == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCmd returns a new initialized instance of the banner command
+func NewCmd(f env.Factory) *cobra.Command {
+	o := NewBannerOptions(f.Streams())
+
+	cmd := &cobra.Command{
+		Use:                   "banner [TEXT]",
+		DisableFlagsInUseLine: true,
+		Short:                 "renders FIGlet banners for generated files",
+		Long:                  "renders FIGlet banners framed by decoration lines and commented for the target file",
+		Example: `
+		To print a banner for a shell script:
+			devctl-banner Exports
+		To print a banner for a go file using the slant font:
+			devctl-banner --kind go --font slant "devctl kit"
+		To add the generator of a file:
+			devctl-banner --subtitle "shell init" --metadata generator=devctl Exports
+		To replace the banner at the top of a file:
+			devctl-banner --in-place .zshrc "ZSH"
+		To list the available fonts:
+			devctl-banner --list-fonts
+		To preview a text in every font:
+			devctl-banner --preview Hello`,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.ValidateArgs(cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Text, "text", "t", o.Text, "Text of the banner, defaults to the arguments")
+	cmd.Flags().StringVarP(&o.Font, "font", "f", o.Font, "Name of the embedded FIGlet font")
+	cmd.Flags().StringVarP(&o.Kind, "kind", "k", o.Kind, fmt.Sprintf("Comment syntax of the banner (%s)", strings.Join(banner.Kinds(), ", ")))
+	cmd.Flags().StringVar(&o.Frame, "frame", o.Frame, fmt.Sprintf("Style of the decoration lines (%s)", strings.Join(banner.FrameStyles(), ", ")))
+	cmd.Flags().StringVar(&o.Layout, "layout", o.Layout, "Horizontal layout (full, default, fitting, smushing, universal)")
+	cmd.Flags().IntVarP(&o.Width, "width", "w", o.Width, "Wrap the text to fit the banner into width columns, 0 disables wrapping")
+	cmd.Flags().StringVar(&o.Subtitle, "subtitle", o.Subtitle, "Plain text rendered below the banner")
+	cmd.Flags().StringArrayVar(&o.Metadata, "metadata", o.Metadata, "KEY=VALUE field rendered below the banner, can be repeated")
+	cmd.Flags().StringVarP(&o.InPlace, "in-place", "i", o.InPlace, "Replace or insert the banner at the top of FILE instead of printing it, requires a frame")
+	cmd.Flags().BoolVar(&o.ListFonts, "list-fonts", o.ListFonts, "List the embedded fonts")
+	cmd.Flags().BoolVar(&o.Preview, "preview", o.Preview, "Render the text in every embedded font")
+
+	return cmd
+}
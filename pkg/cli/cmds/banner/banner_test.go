@@ -0,0 +1,97 @@
+package banner_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/cli"
+	cmdbanner "github.com/alex-held/devctl-kit/pkg/cli/cmds/banner"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+func execute(t *testing.T, args ...string) string {
+	out := &bytes.Buffer{}
+	cmd := cmdbanner.NewCmd(env.NewFactory(env.WithIO(&bytes.Buffer{}, out, out)))
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+	return out.String()
+}
+
+func TestBanner_Print(t *testing.T) {
+	assert.Equal(t, banner.GenerateBanner("ZSH", banner.KIND_SHELL), execute(t, "ZSH"))
+	assert.Equal(t,
+		banner.GenerateBanner("devctl kit", banner.KIND_GO, banner.WithFont("slant"), banner.WithFrame(banner.FrameSolid)),
+		execute(t, "--kind", "go", "--font", "slant", "--frame", "solid", "devctl", "kit"))
+}
+
+func TestBanner_Width(t *testing.T) {
+	out := execute(t, "--width", "70", "--frame", "none", "--text", "devctl kit")
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		assert.LessOrEqual(t, len(strings.TrimPrefix(line, "# ")), 70, line)
+	}
+	assert.Equal(t, banner.GenerateBanner("devctl\nkit", banner.KIND_SHELL, banner.WithFrame(banner.FrameNone)), out)
+}
+
+func TestBanner_ListFonts(t *testing.T) {
+	assert.Equal(t, strings.Join(banner.Fonts(), "\n")+"\n", execute(t, "--list-fonts"))
+}
+
+func TestBanner_Preview(t *testing.T) {
+	out := execute(t, "--preview", "--kind", "terminal", "Hi")
+	for _, name := range banner.Fonts() {
+		assert.Contains(t, out, name+":\n"+banner.GenerateBanner("Hi", banner.KIND_TERMINAL, banner.WithFont(name)))
+	}
+}
+
+func TestBanner_InPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "init.go")
+	require.NoError(t, ioutil.WriteFile(path, []byte("package init\n"), 0600))
+
+	execute(t, "--in-place", path, "Init")
+	first := banner.GenerateBanner("Init", banner.KIND_GO)
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, first+"\npackage init\n", string(b))
+
+	// the existing banner is replaced
+	execute(t, "--in-place", path, "Setup")
+	b, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, banner.GenerateBanner("Setup", banner.KIND_GO)+"\npackage init\n", string(b))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// running it again does not change the file
+	execute(t, "--in-place", path, "Setup")
+	again, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(b), string(again))
+}
+
+func TestBanner_InPlace_FrameNone(t *testing.T) {
+	f := env.NewFactory()
+	cmd := cmdbanner.NewCmd(f)
+	o := cmdbanner.NewBannerOptions(cli.IOStreams{})
+	o.InPlace, o.Frame = filepath.Join(t.TempDir(), "init.go"), "none"
+	require.NoError(t, o.Complete(f, cmd, []string{"Init"}))
+	err := o.ValidateArgs(cmd, []string{"Init"})
+	assert.EqualError(t, err, "--in-place requires decoration lines, banners without a frame cannot be replaced\nSee 'banner -h' for help and examples")
+}
+
+func TestBanner_Metadata(t *testing.T) {
+	out := execute(t, "--subtitle", "shell init", "--metadata", "generator=devctl", "Exports")
+	p, err := banner.Parse(out)
+	require.NoError(t, err)
+	assert.Equal(t, "shell init", p.Subtitle)
+	assert.Equal(t, []banner.Field{{Key: "generator", Value: "devctl"}}, p.Metadata)
+}
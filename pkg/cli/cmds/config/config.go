@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alex-held/devctl-kit/pkg/cli"
+	"github.com/alex-held/devctl-kit/pkg/cli/util"
+	"github.com/alex-held/devctl-kit/pkg/config"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+type ExplainOptions struct {
+	cli.IOStreams
+
+	Key string
+
+	config *config.Config
+}
+
+// NewExplainOptions returns an initialized ExplainOptions instance
+func NewExplainOptions(streams cli.IOStreams) *ExplainOptions {
+	return &ExplainOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *ExplainOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) (err error) {
+	if len(args) > 0 {
+		o.Key = args[0]
+	}
+	o.config, err = f.Config()
+	return err
+}
+
+// ValidateArgs makes sure there is no discrepancy in command options
+func (o *ExplainOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return util.UsageErrorf(cmd, "exactly one key is required")
+	}
+	if _, err := o.config.Get(o.Key); err != nil {
+		return util.UsageErrorf(cmd, "%v", err)
+	}
+	return nil
+}
+
+// Run prints the value of the key followed by the sources it was set by, starting
+// with the one in effect
+func (o *ExplainOptions) Run() error {
+	origins, err := o.config.Explain(o.Key)
+	if err != nil {
+		return err
+	}
+	value, _ := o.config.Get(o.Key)
+	fmt.Fprintf(o.Out, "%s=%s\n", o.Key, value)
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	for i, origin := range origins {
+		note := ""
+		if i > 0 {
+			note = "\t(overridden)"
+		}
+		fmt.Fprintf(w, "  %s\t%s%s\n", origin, origin.Value, note)
+	}
+	return w.Flush()
+}
+
+type ListOptions struct {
+	cli.IOStreams
+
+	config *config.Config
+}
+
+// NewListOptions returns an initialized ListOptions instance
+func NewListOptions(streams cli.IOStreams) *ListOptions {
+	return &ListOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *ListOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) (err error) {
+	o.config, err = f.Config()
+	return err
+}
+
+// Run prints every key with its value and the source in effect
+func (o *ListOptions) Run() error {
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	for _, key := range config.Keys() {
+		value, _ := o.config.Get(key)
+		origins, _ := o.config.Explain(key)
+		fmt.Fprintf(w, "%s=%s\t# %s\n", key, value, origins[0])
+	}
+	return w.Flush()
+}
+
+// NewCmd returns a new initialized instance of the config command
+func NewCmd(f env.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "inspects the devctl configuration",
+		Long: fmt.Sprintf(`inspects the devctl configuration
+
+The configuration is loaded from the defaults, the global file %s in the config
+directory, the project file %s in the working directory or its parents,
+DEVCTL_* environment variables and flags. Later sources override earlier ones.`, config.GlobalFile, config.ProjectFile),
+		Run: util.DefaultSubCommandRun(f.Streams().ErrOut),
+	}
+	cmd.AddCommand(newExplainCmd(f), newListCmd(f))
+	return cmd
+}
+
+func newExplainCmd(f env.Factory) *cobra.Command {
+	o := NewExplainOptions(f.Streams())
+	return &cobra.Command{
+		Use:                   "explain KEY",
+		DisableFlagsInUseLine: true,
+		Short:                 "explains where the value of a key came from",
+		Example: `
+		To explain which source sets the log level:
+			devctl-config explain log.level`,
+		ValidArgs: config.Keys(),
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.ValidateArgs(cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
+
+func newListCmd(f env.Factory) *cobra.Command {
+	o := NewListOptions(f.Streams())
+	return &cobra.Command{
+		Use:   "list",
+		Short: "lists all keys with their values and sources",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.RequireNoArguments(cmd, args)
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package config_test
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cmdconfig "github.com/alex-held/devctl-kit/pkg/cli/cmds/config"
+	"github.com/alex-held/devctl-kit/pkg/config"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+)
+
+func TestExplain(t *testing.T) {
+	e := envtest.New(t)
+	project := filepath.Join(e.WorkingDir, config.ProjectFile)
+	require.NoError(t, afero.WriteFile(e.Fs, project, []byte("log:\n  level: WARN\n"), 0644))
+	e.Environ["DEVCTL_LOG_LEVEL"] = "DEBUG"
+
+	cmd := cmdconfig.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"explain", "log.level"})
+	require.NoError(t, cmd.Execute())
+
+	lines := strings.Split(e.Out.String(), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "log.level=DEBUG", lines[0])
+	assert.Regexp(t, `^  env DEVCTL_LOG_LEVEL +DEBUG$`, lines[1])
+	assert.Regexp(t, `^  project `+regexp.QuoteMeta(project)+`:2 +WARN +\(overridden\)$`, lines[2])
+	assert.Regexp(t, `^  default +INFO +\(overridden\)$`, lines[3])
+}
+
+func TestList(t *testing.T) {
+	e := envtest.New(t)
+	e.Environ["DEVCTL_LOG_COLOR"] = "false"
+
+	cmd := cmdconfig.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"list"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, ""+
+		"index.name=default                                       # default\n"+
+		"index.uri=https://github.com/alex-held/devctl-index.git  # default\n"+
+		"log.color=false                                          # env DEVCTL_LOG_COLOR\n"+
+		"log.level=INFO                                           # default\n",
+		e.Out.String())
+}
@@ -0,0 +1,241 @@
+package index
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alex-held/devctl-kit/pkg/cli"
+	"github.com/alex-held/devctl-kit/pkg/cli/util"
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/index"
+)
+
+type AddOptions struct {
+	cli.IOStreams
+
+	Name string
+	URI  string
+
+	manager *index.Manager
+}
+
+// NewAddOptions returns an initialized AddOptions instance
+func NewAddOptions(streams cli.IOStreams) *AddOptions {
+	return &AddOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *AddOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.manager = index.NewManager(f)
+	if len(args) == 2 {
+		o.Name, o.URI = args[0], args[1]
+	}
+	return nil
+}
+
+// ValidateArgs makes sure there is no discrepancy in command options
+func (o *AddOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return util.UsageErrorf(cmd, "a name and a git uri are required")
+	}
+	if err := index.ValidateName(o.Name); err != nil {
+		return util.UsageErrorf(cmd, "%v", err)
+	}
+	return nil
+}
+
+// Run clones the index
+func (o *AddOptions) Run() error {
+	idx, err := o.manager.Add(o.Name, o.URI)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "added index %s at %s\n", idx.Name, shortCommit(idx.Commit))
+	return nil
+}
+
+type UpdateOptions struct {
+	cli.IOStreams
+
+	Names []string
+
+	manager *index.Manager
+}
+
+// NewUpdateOptions returns an initialized UpdateOptions instance
+func NewUpdateOptions(streams cli.IOStreams) *UpdateOptions {
+	return &UpdateOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *UpdateOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.manager = index.NewManager(f)
+	o.Names = args
+	return nil
+}
+
+// Run updates the indexes, printing the ones updated before a failure
+func (o *UpdateOptions) Run() error {
+	updated, err := o.manager.Update(o.Names...)
+	for _, idx := range updated {
+		fmt.Fprintf(o.Out, "updated index %s to %s\n", idx.Name, shortCommit(idx.Commit))
+	}
+	return err
+}
+
+type ListOptions struct {
+	cli.IOStreams
+
+	manager *index.Manager
+}
+
+// NewListOptions returns an initialized ListOptions instance
+func NewListOptions(streams cli.IOStreams) *ListOptions {
+	return &ListOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *ListOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.manager = index.NewManager(f)
+	return nil
+}
+
+// Run prints the indexes with their uri, commit and time of the last update
+func (o *ListOptions) Run() error {
+	indexes, err := o.manager.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tURI\tCOMMIT\tUPDATED")
+	for _, idx := range indexes {
+		updated := ""
+		if !idx.UpdatedAt.IsZero() {
+			updated = idx.UpdatedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", idx.Name, idx.URI, shortCommit(idx.Commit), updated)
+	}
+	return w.Flush()
+}
+
+type RemoveOptions struct {
+	cli.IOStreams
+
+	Name string
+
+	manager *index.Manager
+}
+
+// NewRemoveOptions returns an initialized RemoveOptions instance
+func NewRemoveOptions(streams cli.IOStreams) *RemoveOptions {
+	return &RemoveOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *RemoveOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.manager = index.NewManager(f)
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	return nil
+}
+
+// ValidateArgs makes sure there is no discrepancy in command options
+func (o *RemoveOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return util.UsageErrorf(cmd, "exactly one index name is required")
+	}
+	return nil
+}
+
+// Run removes the index
+func (o *RemoveOptions) Run() error {
+	if err := o.manager.Remove(o.Name); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "removed index %s\n", o.Name)
+	return nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// NewCmd returns a new initialized instance of the index command
+func NewCmd(f env.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "manages the plugin indexes",
+		Long: fmt.Sprintf(`manages the plugin indexes
+
+An index is a git repository containing plugin manifests. The default index is
+configured by index.name and index.uri, which can be overridden by %s.`, constants.DEVCTL_DEFAULT_INDEX_URI_KEY),
+		Run: util.DefaultSubCommandRun(f.Streams().ErrOut),
+	}
+	cmd.AddCommand(newAddCmd(f), newUpdateCmd(f), newListCmd(f), newRemoveCmd(f))
+	return cmd
+}
+
+func newAddCmd(f env.Factory) *cobra.Command {
+	o := NewAddOptions(f.Streams())
+	return &cobra.Command{
+		Use:                   "add NAME URI",
+		DisableFlagsInUseLine: true,
+		Short:                 "adds an index",
+		Example: `
+		To add a company index:
+			devctl-index add work https://git.example.com/devctl-index.git`,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.ValidateArgs(cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
+
+func newUpdateCmd(f env.Factory) *cobra.Command {
+	o := NewUpdateOptions(f.Streams())
+	return &cobra.Command{
+		Use:                   "update [NAME...]",
+		DisableFlagsInUseLine: true,
+		Short:                 "updates the indexes, adding the default index if it is missing",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
+
+func newListCmd(f env.Factory) *cobra.Command {
+	o := NewListOptions(f.Streams())
+	return &cobra.Command{
+		Use:   "list",
+		Short: "lists the indexes",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.RequireNoArguments(cmd, args)
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
+
+func newRemoveCmd(f env.Factory) *cobra.Command {
+	o := NewRemoveOptions(f.Streams())
+	return &cobra.Command{
+		Use:                   "remove NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "removes an index",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.ValidateArgs(cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package index_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/cli/cmds/index"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+)
+
+func newRepo(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	require.NoError(t, afero.WriteFile(afero.NewOsFs(), filepath.Join(dir, "README.md"), []byte("index\n"), 0644))
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "--all"},
+		{"-c", "user.name=devctl", "-c", "user.email=devctl@example.com", "commit", "--quiet", "--message", "init"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return "file://" + filepath.ToSlash(dir)
+}
+
+func TestAddListRemove(t *testing.T) {
+	uri := newRepo(t)
+	e := envtest.New(t, env.WithFs(afero.NewOsFs()))
+
+	cmd := index.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"add", "work", uri})
+	require.NoError(t, cmd.Execute())
+	assert.Regexp(t, `^added index work at [0-9a-f]{7}\n$`, e.Out.String())
+
+	e.Out.Reset()
+	cmd = index.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"list"})
+	require.NoError(t, cmd.Execute())
+	assert.Regexp(t, `^NAME +URI +COMMIT +UPDATED\nwork +`+uri+` +[0-9a-f]{7} +\S+\n$`, e.Out.String())
+
+	e.Out.Reset()
+	cmd = index.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"remove", "work"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "removed index work\n", e.Out.String())
+}
@@ -0,0 +1,163 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/cli"
+	"github.com/alex-held/devctl-kit/pkg/cli/util"
+	"github.com/alex-held/devctl-kit/pkg/config"
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+type ListOptions struct {
+	cli.IOStreams
+
+	paths    env.Paths
+	profiles []string
+}
+
+// NewListOptions returns an initialized ListOptions instance
+func NewListOptions(streams cli.IOStreams) *ListOptions {
+	return &ListOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *ListOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) (err error) {
+	o.paths = f.Paths()
+	o.profiles, err = o.paths.Profiles(f.Fs())
+	return err
+}
+
+// Run prints the profiles, marking the selected one with an asterisk
+func (o *ListOptions) Run() error {
+	for _, name := range o.profiles {
+		marker := " "
+		if name == o.paths.Profile() {
+			marker = "*"
+		}
+		if _, err := fmt.Fprintf(o.Out, "%s %s\n", marker, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type CreateOptions struct {
+	cli.IOStreams
+
+	Name      string
+	IndexName string
+	IndexURI  string
+
+	f env.Factory
+}
+
+// NewCreateOptions returns an initialized CreateOptions instance
+func NewCreateOptions(streams cli.IOStreams) *CreateOptions {
+	return &CreateOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *CreateOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.f = f
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	return nil
+}
+
+// ValidateArgs makes sure there is no discrepancy in command options
+func (o *CreateOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return util.UsageErrorf(cmd, "exactly one profile name is required")
+	}
+	if err := env.ValidateProfile(o.Name); err != nil {
+		return util.UsageErrorf(cmd, "%v", err)
+	}
+	return nil
+}
+
+// Run creates the layout of the profile and writes its configuration file
+func (o *CreateOptions) Run() error {
+	fs := o.f.Fs()
+	paths, err := o.f.Paths().CreateProfile(fs, o.Name)
+	if err != nil {
+		return err
+	}
+
+	if o.IndexName != "" || o.IndexURI != "" {
+		c := profileConfig{}
+		c.Index.Name, c.Index.URI = o.IndexName, o.IndexURI
+		b, err := yaml.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if err = env.WriteFileAtomic(fs, paths.ProfileConfig(config.GlobalFile), b, 0600); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(o.Out, "created profile %s, select it with %s=%s or --%s %s\n", o.Name, constants.DEVCTL_ENV_KEY, o.Name, env.ProfileFlag, o.Name)
+	fmt.Fprintf(o.Out, "add %s to your PATH\n", paths.BinPath())
+	return nil
+}
+
+// profileConfig contains the keys set by create
+type profileConfig struct {
+	Index struct {
+		Name string `yaml:"name,omitempty"`
+		URI  string `yaml:"uri,omitempty"`
+	} `yaml:"index"`
+}
+
+// NewCmd returns a new initialized instance of the profile command
+func NewCmd(f env.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "manages the devctl profiles",
+		Long: fmt.Sprintf(`manages the devctl profiles
+
+A profile isolates SDKs, installs and binaries and layers its own configuration file
+over the global one. It is selected by %s or --%s.`, constants.DEVCTL_ENV_KEY, env.ProfileFlag),
+		Run: util.DefaultSubCommandRun(f.Streams().ErrOut),
+	}
+	cmd.AddCommand(newListCmd(f), newCreateCmd(f))
+	return cmd
+}
+
+func newListCmd(f env.Factory) *cobra.Command {
+	o := NewListOptions(f.Streams())
+	return &cobra.Command{
+		Use:   "list",
+		Short: "lists the profiles",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.RequireNoArguments(cmd, args)
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
+
+func newCreateCmd(f env.Factory) *cobra.Command {
+	o := NewCreateOptions(f.Streams())
+	cmd := &cobra.Command{
+		Use:                   "create NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "creates a profile",
+		Example: `
+		To create a profile using a company index:
+			devctl-profile create work --index-name work --index-uri https://git.example.com/devctl-index.git`,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.ValidateArgs(cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().StringVar(&o.IndexName, "index-name", o.IndexName, "Name of the default plugin index of the profile")
+	cmd.Flags().StringVar(&o.IndexURI, "index-uri", o.IndexURI, "Git URI of the default plugin index of the profile")
+	return cmd
+}
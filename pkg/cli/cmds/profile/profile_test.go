@@ -0,0 +1,43 @@
+package profile_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/cli/cmds/profile"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+)
+
+func TestCreateAndList(t *testing.T) {
+	e := envtest.New(t)
+
+	cmd := profile.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"create", "work", "--index-uri", "file:///work.git"})
+	require.NoError(t, cmd.Execute())
+
+	b, err := afero.ReadFile(e.Fs, e.Paths.WithProfile("work").ProfileConfig("config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "index:\n    uri: file:///work.git\n", string(b))
+
+	e.Out.Reset()
+	cmd = profile.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"list"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "* default\n  work\n", e.Out.String())
+}
+
+func TestList_Selected(t *testing.T) {
+	e := envtest.New(t)
+	_, err := e.Paths.CreateProfile(e.Fs, "ci")
+	require.NoError(t, err)
+
+	e = envtest.New(t, env.WithFs(e.Fs), env.WithPaths(e.Paths.WithProfile("ci")))
+	cmd := profile.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"list"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "* ci\n  default\n", e.Out.String())
+}
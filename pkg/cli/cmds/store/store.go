@@ -0,0 +1,166 @@
+package store
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alex-held/devctl-kit/pkg/blob"
+	"github.com/alex-held/devctl-kit/pkg/cli"
+	"github.com/alex-held/devctl-kit/pkg/cli/util"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+type ListOptions struct {
+	cli.IOStreams
+
+	store *blob.Store
+}
+
+// NewListOptions returns an initialized ListOptions instance
+func NewListOptions(streams cli.IOStreams) *ListOptions {
+	return &ListOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *ListOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.store = blob.NewStore(f)
+	return nil
+}
+
+// Run prints the blobs with their size
+func (o *ListOptions) Run() error {
+	blobs, err := o.store.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SHA256\tSIZE")
+	for _, b := range blobs {
+		fmt.Fprintf(w, "%s\t%s\n", b.Sum, blob.FormatSize(b.Size))
+	}
+	return w.Flush()
+}
+
+type VerifyOptions struct {
+	cli.IOStreams
+
+	Remove bool
+
+	store *blob.Store
+}
+
+// NewVerifyOptions returns an initialized VerifyOptions instance
+func NewVerifyOptions(streams cli.IOStreams) *VerifyOptions {
+	return &VerifyOptions{IOStreams: streams}
+}
+
+// Complete completes all the required options
+func (o *VerifyOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.store = blob.NewStore(f)
+	return nil
+}
+
+// Run verifies the blobs and fails if corrupt ones are kept
+func (o *VerifyOptions) Run() error {
+	report, err := o.store.Verify(blob.VerifyOptions{Remove: o.Remove})
+	if report != nil {
+		fmt.Fprint(o.Out, report)
+	}
+	if err == nil && len(report.Corrupt) > 0 && !o.Remove {
+		err = errors.Errorf("the store contains %d corrupt blobs, run verify --remove to download them again", len(report.Corrupt))
+	}
+	return err
+}
+
+type PruneOptions struct {
+	cli.IOStreams
+
+	DryRun bool
+	MinAge time.Duration
+
+	store *blob.Store
+}
+
+// NewPruneOptions returns an initialized PruneOptions instance
+func NewPruneOptions(streams cli.IOStreams) *PruneOptions {
+	return &PruneOptions{IOStreams: streams, MinAge: time.Hour}
+}
+
+// Complete completes all the required options
+func (o *PruneOptions) Complete(f env.Factory, cmd *cobra.Command, args []string) error {
+	o.store = blob.NewStore(f)
+	return nil
+}
+
+// Run prunes the blobs which are not referenced by a receipt
+func (o *PruneOptions) Run() error {
+	report, err := o.store.Prune(blob.PruneOptions{DryRun: o.DryRun, MinAge: o.MinAge})
+	if report != nil {
+		fmt.Fprint(o.Out, report)
+	}
+	return err
+}
+
+// NewCmd returns a new initialized instance of the store command
+func NewCmd(f env.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "manages the store of downloaded artifacts",
+		Long: `manages the store of downloaded artifacts
+
+Artifacts are stored by their sha256 and shared by all versions and profiles, so
+that an artifact is downloaded once. The store is pruned of the artifacts which are
+not referenced by an install receipt of any profile.`,
+		Run: util.DefaultSubCommandRun(f.Streams().ErrOut),
+	}
+	cmd.AddCommand(newListCmd(f), newVerifyCmd(f), newPruneCmd(f))
+	return cmd
+}
+
+func newListCmd(f env.Factory) *cobra.Command {
+	o := NewListOptions(f.Streams())
+	return &cobra.Command{
+		Use:   "list",
+		Short: "lists the stored artifacts",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.RequireNoArguments(cmd, args)
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+}
+
+func newVerifyCmd(f env.Factory) *cobra.Command {
+	o := NewVerifyOptions(f.Streams())
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "verifies the checksums of the stored artifacts",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.RequireNoArguments(cmd, args)
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().BoolVar(&o.Remove, "remove", o.Remove, "Remove corrupt artifacts, so that they are downloaded again")
+	return cmd
+}
+
+func newPruneCmd(f env.Factory) *cobra.Command {
+	o := NewPruneOptions(f.Streams())
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "removes the artifacts which are not referenced by an install receipt",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.RequireNoArguments(cmd, args)
+			util.CheckErr(o.Complete(f, cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the artifacts without removing them")
+	cmd.Flags().DurationVar(&o.MinAge, "min-age", o.MinAge, "Keep artifacts younger than this, as they may belong to an install in progress")
+	return cmd
+}
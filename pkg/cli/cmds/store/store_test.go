@@ -0,0 +1,39 @@
+package store_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/blob"
+	"github.com/alex-held/devctl-kit/pkg/cli/cmds/store"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+)
+
+func TestListVerifyPrune(t *testing.T) {
+	e := envtest.New(t)
+	h := sha256.Sum256([]byte("artifact"))
+	sum := hex.EncodeToString(h[:])
+	require.NoError(t, blob.NewStore(e.Factory).Put(sum, strings.NewReader("artifact")))
+
+	cmd := store.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"list"})
+	require.NoError(t, cmd.Execute())
+	assert.Regexp(t, `^SHA256 +SIZE\n`+sum+` +8 B\n$`, e.Out.String())
+
+	e.Out.Reset()
+	cmd = store.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"verify"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "1 blobs verified, 0 corrupt\n", e.Out.String())
+
+	e.Out.Reset()
+	cmd = store.NewCmd(e.Factory)
+	cmd.SetArgs([]string{"prune", "--dry-run", "--min-age", "0s"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "       8 B "+e.Paths.Blob(sum)+"\n8 B reclaimable\n", e.Out.String())
+}
@@ -0,0 +1,206 @@
+// Package config loads the devctl configuration from layered sources and tracks
+// where each value came from.
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/log"
+)
+
+// Config is the typed devctl configuration.
+//
+// Every leaf field is a key named by the yaml tags of its path, e.g. "index.uri".
+// The env tag overrides the environment variable of a key, which defaults to DEVCTL_
+// followed by the upper case key with dots replaced by underscores, e.g. DEVCTL_LOG_LEVEL.
+// Keys with a flag tag can be set by the command line flag of that name, see AddFlags.
+type Config struct {
+	Index Index `yaml:"index"`
+	Log   Log   `yaml:"log"`
+
+	origins map[string][]Origin
+}
+
+type Index struct {
+	Name string `yaml:"name" usage:"Name of the default plugin index"`
+	URI  string `yaml:"uri" env:"DEVCTL_DEFAULT_INDEX_URI" flag:"index-uri" usage:"Git URI of the default plugin index"`
+}
+
+type Log struct {
+	Color bool   `yaml:"color" flag:"log-color" usage:"Colorize log messages"`
+	Level string `yaml:"level" flag:"log-level" usage:"Minimum level of log messages (DEBUG, INFO, WARN, ERROR, FATAL)"`
+}
+
+// Default returns the built-in defaults
+func Default() *Config {
+	return &Config{
+		Index: Index{
+			Name: constants.DefaultIndexName,
+			URI:  constants.DefaultIndexURI,
+		},
+		Log: Log{
+			Color: true,
+			Level: log.Info.String(),
+		},
+	}
+}
+
+// Validate checks the values of the keys
+func (c *Config) Validate() error {
+	if _, err := log.ParseLevel(strings.ToUpper(c.Log.Level)); err != nil {
+		return errors.Errorf("invalid log.level %q", c.Log.Level)
+	}
+	if c.Index.Name == "" {
+		return errors.New("index.name must not be empty")
+	}
+	return nil
+}
+
+// SourceKind is the layer a value was loaded from, in increasing precedence
+type SourceKind string
+
+const (
+	SourceDefault SourceKind = "default"
+	SourceGlobal  SourceKind = "global"
+	SourceProfile SourceKind = "profile"
+	SourceProject SourceKind = "project"
+	SourceEnv     SourceKind = "env"
+	SourceFlag    SourceKind = "flag"
+)
+
+// Origin is a value of a key and where it was set
+type Origin struct {
+	Kind SourceKind
+	// Location is the file and line, environment variable or flag that set the value
+	Location string
+	Value    string
+}
+
+func (o Origin) String() string {
+	if o.Location == "" {
+		return string(o.Kind)
+	}
+	return fmt.Sprintf("%s %s", o.Kind, o.Location)
+}
+
+// Keys returns the sorted keys of the configuration
+func Keys() []string {
+	var keys []string
+	for _, f := range fields(Default()) {
+		keys = append(keys, f.key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value of key formatted as a string
+func (c *Config) Get(key string) (string, error) {
+	f, err := lookup(c, key)
+	if err != nil {
+		return "", err
+	}
+	return f.String(), nil
+}
+
+// Set sets key to value, which is parsed according to the type of the key
+func (c *Config) Set(key, value string) error {
+	f, err := lookup(c, key)
+	if err != nil {
+		return err
+	}
+	return f.set(value)
+}
+
+// Explain returns the values of key in order of precedence, i.e. the first Origin
+// set the current value and overrides the others
+func (c *Config) Explain(key string) ([]Origin, error) {
+	if _, err := lookup(c, key); err != nil {
+		return nil, err
+	}
+	origins := c.origins[key]
+	explained := make([]Origin, 0, len(origins))
+	for i := len(origins) - 1; i >= 0; i-- {
+		explained = append(explained, origins[i])
+	}
+	return explained, nil
+}
+
+// track records that key was set by source
+func (c *Config) track(key string, kind SourceKind, location string) {
+	if c.origins == nil {
+		c.origins = map[string][]Origin{}
+	}
+	value, _ := c.Get(key)
+	c.origins[key] = append(c.origins[key], Origin{Kind: kind, Location: location, Value: value})
+}
+
+// field is a key of the configuration backed by a field of a Config
+type field struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	value reflect.Value
+}
+
+func (f field) String() string {
+	if f.value.Kind() == reflect.String {
+		return f.value.String()
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// set parses value as YAML, except for strings, which are taken literally
+func (f field) set(value string) error {
+	if f.value.Kind() == reflect.String {
+		f.value.SetString(value)
+		return nil
+	}
+	if err := yaml.Unmarshal([]byte(value), f.value.Addr().Interface()); err != nil {
+		return errors.Errorf("invalid value %q for %s, expected a %s", value, f.key, f.value.Kind())
+	}
+	return nil
+}
+
+// fields returns the keys of c in declaration order
+func fields(c *Config) []field {
+	return walk(reflect.ValueOf(c).Elem(), "")
+}
+
+func walk(v reflect.Value, prefix string) (out []field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if sf.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		if sf.Type.Kind() == reflect.Struct {
+			out = append(out, walk(v.Field(i), key+".")...)
+			continue
+		}
+		env := sf.Tag.Get("env")
+		if env == "" {
+			env = "DEVCTL_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		}
+		out = append(out, field{key: key, env: env, flag: sf.Tag.Get("flag"), usage: sf.Tag.Get("usage"), value: v.Field(i)})
+	}
+	return out
+}
+
+func lookup(c *Config, key string) (field, error) {
+	for _, f := range fields(c) {
+		if f.key == key {
+			return f, nil
+		}
+	}
+	return field{}, errors.Errorf("unknown key %q, expected one of %s", key, strings.Join(Keys(), ", "))
+}
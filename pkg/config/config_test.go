@@ -0,0 +1,132 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/config"
+	"github.com/alex-held/devctl-kit/pkg/constants"
+)
+
+func environ(vars map[string]string) config.LoaderOption {
+	return config.WithLookupEnv(func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	})
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	c, err := config.NewLoader(afero.NewMemMapFs(), "/config", config.WithWorkingDir("/work"), environ(nil)).Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, constants.DefaultIndexURI, c.Index.URI)
+	assert.Equal(t, "INFO", c.Log.Level)
+	assert.True(t, c.Log.Color)
+
+	origins, err := c.Explain("log.level")
+	require.NoError(t, err)
+	assert.Equal(t, []config.Origin{{Kind: config.SourceDefault, Value: "INFO"}}, origins)
+}
+
+func TestLoad_Layers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config/config.yaml", []byte("log:\n  level: WARN\n  color: false\nindex:\n  name: global\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/work/.devctl.yaml", []byte("# project\nlog:\n  level: ERROR\n"), 0644))
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	config.AddFlags(flags)
+	require.NoError(t, flags.Parse([]string{"--index-uri", "file:///flag"}))
+
+	c, err := config.NewLoader(fs, "/config",
+		config.WithWorkingDir("/work/nested/dir"),
+		config.WithFlags(flags),
+		environ(map[string]string{
+			"DEVCTL_LOG_LEVEL":         "DEBUG",
+			"DEVCTL_DEFAULT_INDEX_URI": "file:///env",
+		}),
+	).Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "global", c.Index.Name)
+	assert.Equal(t, "file:///flag", c.Index.URI)
+	assert.Equal(t, "DEBUG", c.Log.Level)
+	assert.False(t, c.Log.Color)
+
+	origins, err := c.Explain("log.level")
+	require.NoError(t, err)
+	assert.Equal(t, []config.Origin{
+		{Kind: config.SourceEnv, Location: "DEVCTL_LOG_LEVEL", Value: "DEBUG"},
+		{Kind: config.SourceProject, Location: "/work/.devctl.yaml:3", Value: "ERROR"},
+		{Kind: config.SourceGlobal, Location: "/config/config.yaml:2", Value: "WARN"},
+		{Kind: config.SourceDefault, Value: "INFO"},
+	}, origins)
+
+	origins, err = c.Explain("index.uri")
+	require.NoError(t, err)
+	assert.Equal(t, "flag --index-uri", origins[0].String())
+	assert.Equal(t, "env DEVCTL_DEFAULT_INDEX_URI", origins[1].String())
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := map[string]struct {
+		global string
+		env    map[string]string
+		err    string
+	}{
+		"unknown key":     {global: "log:\n  colour: true\n", err: `/config/config.yaml:2: unknown key "log.colour"`},
+		"invalid value":   {global: "log:\n  color: maybe\n", err: "/config/config.yaml:2: invalid value for log.color, expected a bool"},
+		"not a mapping":   {global: "- log\n", err: "/config/config.yaml:1: expected a mapping"},
+		"invalid env":     {env: map[string]string{"DEVCTL_LOG_COLOR": "maybe"}, err: "invalid environment variable DEVCTL_LOG_COLOR"},
+		"invalid level":   {global: "log:\n  level: LOUD\n", err: `invalid log.level "LOUD"`},
+		"empty index key": {env: map[string]string{"DEVCTL_INDEX_NAME": ""}, global: "index:\n  name: ''\n", err: "index.name must not be empty"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/config/config.yaml", []byte(tt.global), 0644))
+
+			_, err := config.NewLoader(fs, "/config", config.WithWorkingDir("/"), environ(tt.env)).Load()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestConfig_GetSet(t *testing.T) {
+	c := config.Default()
+	require.NoError(t, c.Set("log.color", "false"))
+	require.NoError(t, c.Set("index.name", "true"))
+
+	value, err := c.Get("log.color")
+	require.NoError(t, err)
+	assert.Equal(t, "false", value)
+	assert.Equal(t, "true", c.Index.Name)
+
+	assert.Error(t, c.Set("log.color", "maybe"))
+	_, err = c.Get("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"index.name", "index.uri", "log.color", "log.level"}, config.Keys())
+}
+
+func TestLoad_Profile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config/config.yaml", []byte("index:\n  name: global\n  uri: file:///global\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/config/profiles/work/config.yaml", []byte("index:\n  uri: file:///work\n"), 0644))
+
+	c, err := config.NewLoader(fs, "/config", config.WithProfile("work"), config.WithWorkingDir("/"), environ(nil)).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "global", c.Index.Name)
+	assert.Equal(t, "file:///work", c.Index.URI)
+
+	origins, err := c.Explain("index.uri")
+	require.NoError(t, err)
+	assert.Equal(t, "profile /config/profiles/work/config.yaml:2", origins[0].String())
+
+	c, err = config.NewLoader(fs, "/config", config.WithProfile("default"), config.WithWorkingDir("/"), environ(nil)).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "file:///global", c.Index.URI)
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+)
+
+const (
+	// GlobalFile is the name of the global configuration file in the config directory
+	GlobalFile = "config.yaml"
+	// ProjectFile is the name of the project configuration file, which is discovered
+	// in the working directory or its parents
+	ProjectFile = ".devctl.yaml"
+)
+
+// Loader loads a Config from its layers, in increasing precedence: the defaults, the
+// GlobalFile, the GlobalFile of the profile, the ProjectFile, environment variables and
+// command line flags
+type Loader struct {
+	fs        afero.Fs
+	configDir string
+	profile   string
+	dir       string
+	lookupEnv func(key string) (string, bool)
+	flags     *pflag.FlagSet
+}
+
+type LoaderOption func(*Loader) *Loader
+
+// WithWorkingDir sets the directory the discovery of the ProjectFile starts in
+func WithWorkingDir(dir string) LoaderOption {
+	return func(l *Loader) *Loader {
+		l.dir = dir
+		return l
+	}
+}
+
+// WithProfile loads the GlobalFile in {configDir}/profiles/{profile} after the global one
+func WithProfile(profile string) LoaderOption {
+	return func(l *Loader) *Loader {
+		l.profile = profile
+		return l
+	}
+}
+
+// WithLookupEnv sets the function environment variables are read with
+func WithLookupEnv(lookupEnv func(key string) (string, bool)) LoaderOption {
+	return func(l *Loader) *Loader {
+		l.lookupEnv = lookupEnv
+		return l
+	}
+}
+
+// WithFlags sets the flags registered by AddFlags. Only changed flags override values.
+func WithFlags(flags *pflag.FlagSet) LoaderOption {
+	return func(l *Loader) *Loader {
+		l.flags = flags
+		return l
+	}
+}
+
+// NewLoader returns a Loader reading the GlobalFile from configDir using fs.
+// By default, the ProjectFile is discovered from the current working directory and
+// environment variables are read from the os.
+func NewLoader(fs afero.Fs, configDir string, opts ...LoaderOption) *Loader {
+	l := &Loader{
+		fs:        fs,
+		configDir: configDir,
+		lookupEnv: os.LookupEnv,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.dir == "" {
+		l.dir, _ = os.Getwd()
+	}
+	return l
+}
+
+// AddFlags registers the flags of the keys with a flag tag
+func AddFlags(flags *pflag.FlagSet) {
+	for _, f := range fields(Default()) {
+		if f.flag == "" || flags.Lookup(f.flag) != nil {
+			continue
+		}
+		usage := fmt.Sprintf("%s (%s)", f.usage, f.key)
+		if b, ok := f.value.Interface().(bool); ok {
+			flags.Bool(f.flag, b, usage)
+		} else {
+			flags.String(f.flag, f.String(), usage)
+		}
+	}
+}
+
+// ProjectFile returns the ProjectFile in the working directory or the closest parent
+func (l *Loader) ProjectFile() (string, bool) {
+	if l.dir == "" {
+		return "", false
+	}
+	dir := filepath.Clean(l.dir)
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if fi, err := l.fs.Stat(path); err == nil && !fi.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Load merges the layers into a Config and validates it
+func (l *Loader) Load() (*Config, error) {
+	c := Default()
+	all := fields(c)
+	for _, f := range all {
+		c.track(f.key, SourceDefault, "")
+	}
+
+	if err := l.loadFile(c, filepath.Join(l.configDir, GlobalFile), SourceGlobal); err != nil {
+		return nil, err
+	}
+	if l.profile != "" && l.profile != constants.DefaultProfile {
+		if err := l.loadFile(c, filepath.Join(l.configDir, constants.ProfilesDir, l.profile, GlobalFile), SourceProfile); err != nil {
+			return nil, err
+		}
+	}
+	if path, ok := l.ProjectFile(); ok {
+		if err := l.loadFile(c, path, SourceProject); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range all {
+		value, ok := l.lookupEnv(f.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := f.set(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid environment variable %s", f.env)
+		}
+		c.track(f.key, SourceEnv, f.env)
+	}
+
+	for _, f := range all {
+		if l.flags == nil || f.flag == "" {
+			continue
+		}
+		flag := l.flags.Lookup(f.flag)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		if err := f.set(flag.Value.String()); err != nil {
+			return nil, errors.Wrapf(err, "invalid flag --%s", f.flag)
+		}
+		c.track(f.key, SourceFlag, "--"+f.flag)
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadFile decodes the keys of the YAML file at path into c, if it exists
+func (l *Loader) loadFile(c *Config, path string, kind SourceKind) error {
+	b, err := afero.ReadFile(l.fs, path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	doc := &yaml.Node{}
+	if err = yaml.Unmarshal(b, doc); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", path)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	byKey := map[string]field{}
+	for _, f := range fields(c) {
+		byKey[f.key] = f
+	}
+	return decode(c, byKey, doc.Content[0], "", path, kind)
+}
+
+func decode(c *Config, byKey map[string]field, node *yaml.Node, prefix, path string, kind SourceKind) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.Errorf("%s:%d: expected a mapping", path, node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		key := prefix + k.Value
+
+		if f, ok := byKey[key]; ok {
+			if err := v.Decode(f.value.Addr().Interface()); err != nil {
+				return errors.Errorf("%s:%d: invalid value for %s, expected a %s", path, v.Line, key, f.value.Kind())
+			}
+			c.track(key, kind, fmt.Sprintf("%s:%d", path, v.Line))
+			continue
+		}
+		if v.Kind == yaml.MappingNode && isSection(byKey, key) {
+			if err := decode(c, byKey, v, key+".", path, kind); err != nil {
+				return err
+			}
+			continue
+		}
+		return errors.Errorf("%s:%d: unknown key %q", path, k.Line, key)
+	}
+	return nil
+}
+
+func isSection(byKey map[string]field, section string) bool {
+	for key := range byKey {
+		if strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -16,6 +16,7 @@ const (
 const (
 	DEVCTL_ROOT_KEY              = "DEVCTL_ROOT"
 	DEVCTL_ENV_KEY               = "DEVCTL_ENV"
+	DEVCTL_LAYOUT_KEY            = "DEVCTL_LAYOUT"
 	DEVCTL_DEFAULT_INDEX_URI_KEY = "DEVCTL_DEFAULT_INDEX_URI"
 )
 
@@ -28,4 +29,20 @@ const (
 	StoreDir         = "store"
 	ReceiptsDir      = "receipts"
 	BinDir           = "bin"
+	CurrentLink      = "current"
+	BlobsDir         = ".blobs"
+	CacheDir         = "cache"
+	StateDir         = "state"
+	LogsDir          = "logs"
+	XDGDir           = "devctl"
+	ProfilesDir      = "profiles"
+	DefaultProfile   = "default"
+)
+
+// XDG Base Directory environment variable keys
+const (
+	XDG_CONFIG_HOME_KEY = "XDG_CONFIG_HOME"
+	XDG_DATA_HOME_KEY   = "XDG_DATA_HOME"
+	XDG_CACHE_HOME_KEY  = "XDG_CACHE_HOME"
+	XDG_STATE_HOME_KEY  = "XDG_STATE_HOME"
 )
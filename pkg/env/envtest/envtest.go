@@ -0,0 +1,91 @@
+// Package envtest provides sandboxed env.Factory instances for unit tests.
+package envtest
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/cli"
+	"github.com/alex-held/devctl-kit/pkg/config"
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/log"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+// DefaultRuntimeInfo is the runtime info reported by the factories of New
+var DefaultRuntimeInfo = system.RuntimeInfo{OS: "linux", Arch: "amd64"}
+
+// RuntimeInfoGetter is a system.RuntimeInfoGetter returning a fixed RuntimeInfo
+type RuntimeInfoGetter system.RuntimeInfo
+
+func (g RuntimeInfoGetter) Get() system.RuntimeInfo { return system.RuntimeInfo(g) }
+
+// Env is a sandboxed environment consisting of an env.Factory and the fakes backing it
+type Env struct {
+	Factory env.Factory
+
+	Fs    afero.Fs
+	Paths env.Paths
+	// WorkingDir is where the project configuration file is discovered from
+	WorkingDir string
+	// Environ contains the environment variables read by the config.Loader
+	Environ map[string]string
+
+	// In is read by commands, Out, ErrOut and Log capture their output
+	In     *bytes.Buffer
+	Out    *bytes.Buffer
+	ErrOut *bytes.Buffer
+	Log    *bytes.Buffer
+}
+
+// New returns an Env backed by an in-memory SymlinkFs, with Paths below a temporary
+// directory, captured IOStreams and logs and DefaultRuntimeInfo. The configuration
+// reads environment variables from Environ instead of the os.
+// The opts are applied last and can replace any of the fakes, e.g. with env.WithFs.
+// Logging a fatal message fails the test.
+func New(t testing.TB, opts ...env.FactoryOption) *Env {
+	t.Helper()
+	dir := t.TempDir()
+	e := &Env{
+		Fs:         NewSymlinkFs(),
+		Paths:      env.NewPaths(filepath.Join(dir, constants.DefaultDevctlDir)),
+		WorkingDir: dir,
+		Environ:    map[string]string{},
+		In:         &bytes.Buffer{},
+		Out:        &bytes.Buffer{},
+		ErrOut:     &bytes.Buffer{},
+		Log:        &bytes.Buffer{},
+	}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := e.Environ[key]
+		return value, ok
+	}
+
+	cfg := &env.FactoryConfig{}
+	defaults := []env.FactoryOption{
+		env.WithFs(e.Fs),
+		env.WithPaths(e.Paths),
+		env.WithStreams(cli.IOStreams{In: e.In, Out: e.Out, ErrOut: e.ErrOut}),
+		env.WithRuntimeInfoGetter(RuntimeInfoGetter(DefaultRuntimeInfo)),
+		env.WithLoggerConfig(&log.Config{
+			Out:       e.Log,
+			FatalFunc: func() { t.Fatalf("fatal message logged:\n%s", e.Log.String()) },
+		}),
+		env.WithConfigOptions(config.WithWorkingDir(e.WorkingDir), config.WithLookupEnv(lookupEnv)),
+	}
+	all := append(defaults, opts...)
+	for _, opt := range all {
+		opt(cfg)
+	}
+	e.Fs, e.Paths = cfg.Fs, cfg.Paths
+
+	if err := e.Fs.MkdirAll(e.Paths.Base(), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", e.Paths.Base(), err)
+	}
+	e.Factory = env.NewFactory(all...)
+	return e
+}
@@ -0,0 +1,48 @@
+package envtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+func TestNew(t *testing.T) {
+	e := envtest.New(t)
+
+	assert.Equal(t, e.Fs, e.Factory.Fs())
+	assert.IsType(t, &envtest.SymlinkFs{}, e.Fs)
+	assert.Equal(t, e.Paths, e.Factory.Paths())
+	assert.Equal(t, envtest.DefaultRuntimeInfo, e.Factory.RuntimeInfo())
+
+	exists, err := afero.DirExists(e.Fs, e.Paths.Base())
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	fmt.Fprint(e.Factory.Streams().Out, "out")
+	fmt.Fprint(e.Factory.Streams().ErrOut, "err")
+	e.Factory.Logger().Infof("logged")
+	assert.Equal(t, "out", e.Out.String())
+	assert.Equal(t, "err", e.ErrOut.String())
+	assert.Contains(t, e.Log.String(), "logged")
+}
+
+func TestNew_Options(t *testing.T) {
+	paths := env.NewPaths("/sandbox/.devctl")
+	info := system.RuntimeInfo{OS: "darwin", Arch: "arm64"}
+	e := envtest.New(t, env.WithPaths(paths), env.WithRuntimeInfoGetter(envtest.RuntimeInfoGetter(info)))
+
+	assert.Equal(t, paths, e.Paths)
+	assert.Equal(t, paths, e.Factory.Paths())
+	assert.Equal(t, info, e.Factory.RuntimeInfo())
+
+	exists, err := afero.DirExists(e.Fs, "/sandbox/.devctl")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
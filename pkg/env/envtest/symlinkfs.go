@@ -0,0 +1,334 @@
+package envtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// maxLinks is the number of symbolic links followed before ELOOP is returned
+const maxLinks = 255
+
+// SymlinkFs is an in-memory afero.Fs supporting symbolic links, i.e. it implements
+// afero.Symlinker. Symbolic links are followed like by the os, except for Lstat,
+// Readlink, Remove and Rename, which operate on the link itself.
+//
+// Links are stored next to an empty placeholder file in the wrapped afero.Fs, so that
+// they are listed by Readdir with os.ModeSymlink set.
+type SymlinkFs struct {
+	afero.Fs
+
+	mu    sync.RWMutex
+	links map[string]string
+}
+
+// NewSymlinkFs returns a SymlinkFs backed by an afero.MemMapFs
+func NewSymlinkFs() *SymlinkFs {
+	return &SymlinkFs{Fs: afero.NewMemMapFs(), links: map[string]string{}}
+}
+
+func (s *SymlinkFs) Name() string { return "SymlinkFs" }
+
+// SymlinkIfPossible creates newname as a symbolic link to oldname
+func (s *SymlinkFs) SymlinkIfPossible(oldname, newname string) error {
+	path, err := s.resolve(newname, false)
+	if err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: errOf(err)}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fi, err := s.Fs.Stat(filepath.Dir(path)); err != nil || !fi.IsDir() {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: os.ErrNotExist}
+	}
+	f, err := s.Fs.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0777)
+	if err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: os.ErrExist}
+	}
+	_ = f.Close()
+	s.links[path] = oldname
+	return nil
+}
+
+// ReadlinkIfPossible returns the target of the symbolic link name
+func (s *SymlinkFs) ReadlinkIfPossible(name string) (string, error) {
+	path, err := s.resolve(name, false)
+	if err != nil {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: errOf(err)}
+	}
+	if target, ok := s.link(path); ok {
+		return target, nil
+	}
+	if _, err := s.Fs.Stat(path); err != nil {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: errOf(err)}
+	}
+	return "", &os.PathError{Op: "readlink", Path: name, Err: syscall.EINVAL}
+}
+
+// LstatIfPossible returns the os.FileInfo of name without following a symbolic link
+func (s *SymlinkFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	path, err := s.resolve(name, false)
+	if err != nil {
+		return nil, true, &os.PathError{Op: "lstat", Path: name, Err: errOf(err)}
+	}
+	fi, err := s.Fs.Stat(path)
+	if err != nil {
+		return nil, true, err
+	}
+	if target, ok := s.link(path); ok {
+		return &linkInfo{FileInfo: fi, target: target}, true, nil
+	}
+	return fi, true, nil
+}
+
+func (s *SymlinkFs) Create(name string) (afero.File, error) {
+	return s.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (s *SymlinkFs) Mkdir(name string, perm os.FileMode) error {
+	path, err := s.resolve(name, false)
+	if err != nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: errOf(err)}
+	}
+	return s.Fs.Mkdir(path, perm)
+}
+
+func (s *SymlinkFs) MkdirAll(name string, perm os.FileMode) error {
+	if fi, err := s.Stat(name); err == nil {
+		if fi.IsDir() {
+			return nil
+		}
+		return &os.PathError{Op: "mkdir", Path: name, Err: syscall.ENOTDIR}
+	}
+	if parent := filepath.Dir(filepath.Clean(name)); parent != name {
+		if err := s.MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	if err := s.Mkdir(name, perm); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *SymlinkFs) Open(name string) (afero.File, error) {
+	return s.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (s *SymlinkFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	path, err := s.resolve(name, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: errOf(err)}
+	}
+	f, err := s.Fs.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &file{File: f, fs: s, path: path}, nil
+}
+
+func (s *SymlinkFs) Remove(name string) error {
+	path, err := s.resolve(name, false)
+	if err != nil {
+		return &os.PathError{Op: "remove", Path: name, Err: errOf(err)}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Fs.Remove(path); err != nil {
+		return err
+	}
+	delete(s.links, path)
+	return nil
+}
+
+func (s *SymlinkFs) RemoveAll(name string) error {
+	path, err := s.resolve(name, false)
+	if err != nil {
+		return &os.PathError{Op: "removeall", Path: name, Err: errOf(err)}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Fs.RemoveAll(path); err != nil {
+		return err
+	}
+	for link := range s.links {
+		if link == path || strings.HasPrefix(link, path+string(filepath.Separator)) {
+			delete(s.links, link)
+		}
+	}
+	return nil
+}
+
+func (s *SymlinkFs) Rename(oldname, newname string) error {
+	oldpath, err := s.resolve(oldname, false)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: errOf(err)}
+	}
+	newpath, err := s.resolve(newname, false)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: errOf(err)}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.links[newpath]; ok {
+		// renaming onto a link replaces the link, not its target
+		if err := s.Fs.Remove(newpath); err != nil {
+			return err
+		}
+		delete(s.links, newpath)
+	}
+	if err := s.renameTree(oldpath, newpath); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: errOf(err)}
+	}
+	for link, target := range s.links {
+		if link == oldpath || strings.HasPrefix(link, oldpath+string(filepath.Separator)) {
+			delete(s.links, link)
+			s.links[newpath+strings.TrimPrefix(link, oldpath)] = target
+		}
+	}
+	return nil
+}
+
+// renameTree renames oldpath to newpath in the wrapped afero.Fs. The entries of a
+// directory are moved one by one, as afero.MemMapFs only renames the directory itself.
+func (s *SymlinkFs) renameTree(oldpath, newpath string) error {
+	fi, err := s.Fs.Stat(oldpath)
+	if err != nil || !fi.IsDir() {
+		return s.Fs.Rename(oldpath, newpath)
+	}
+	if strings.HasPrefix(newpath, oldpath+string(filepath.Separator)) {
+		return syscall.EINVAL
+	}
+	// like os.Rename, existing directories are not replaced
+	if dst, err := s.Fs.Stat(newpath); err == nil {
+		if dst.IsDir() {
+			return syscall.EEXIST
+		}
+		return syscall.ENOTDIR
+	}
+	if err = s.Fs.Mkdir(newpath, fi.Mode().Perm()); err != nil {
+		return err
+	}
+	infos, err := afero.ReadDir(s.Fs, oldpath)
+	if err != nil {
+		return err
+	}
+	for _, child := range infos {
+		if err = s.renameTree(filepath.Join(oldpath, child.Name()), filepath.Join(newpath, child.Name())); err != nil {
+			return err
+		}
+	}
+	return s.Fs.Remove(oldpath)
+}
+
+func (s *SymlinkFs) Stat(name string) (os.FileInfo, error) {
+	path, err := s.resolve(name, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: errOf(err)}
+	}
+	return s.Fs.Stat(path)
+}
+
+func (s *SymlinkFs) Chmod(name string, mode os.FileMode) error {
+	path, err := s.resolve(name, true)
+	if err != nil {
+		return &os.PathError{Op: "chmod", Path: name, Err: errOf(err)}
+	}
+	return s.Fs.Chmod(path, mode)
+}
+
+func (s *SymlinkFs) Chown(name string, uid, gid int) error {
+	path, err := s.resolve(name, true)
+	if err != nil {
+		return &os.PathError{Op: "chown", Path: name, Err: errOf(err)}
+	}
+	return s.Fs.Chown(path, uid, gid)
+}
+
+func (s *SymlinkFs) Chtimes(name string, atime, mtime time.Time) error {
+	path, err := s.resolve(name, true)
+	if err != nil {
+		return &os.PathError{Op: "chtimes", Path: name, Err: errOf(err)}
+	}
+	return s.Fs.Chtimes(path, atime, mtime)
+}
+
+func (s *SymlinkFs) link(path string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	target, ok := s.links[path]
+	return target, ok
+}
+
+// resolve returns the path of name in the wrapped afero.Fs, i.e. with the symbolic
+// links of all parent directories and, if follow is set, of name itself evaluated
+func (s *SymlinkFs) resolve(name string, follow bool) (string, error) {
+	sep := string(filepath.Separator)
+	elems := strings.Split(filepath.ToSlash(name), "/")
+
+	dest := sep
+	for links, i := 0, 0; i < len(elems); i++ {
+		switch elems[i] {
+		case "", ".":
+			continue
+		case "..":
+			dest = filepath.Dir(dest)
+			continue
+		}
+		next := filepath.Join(dest, elems[i])
+		target, ok := s.link(next)
+		if !ok || (i == len(elems)-1 && !follow) {
+			dest = next
+			continue
+		}
+		if links++; links > maxLinks {
+			return "", syscall.ELOOP
+		}
+		// continue with the remaining elements appended to the target of the link,
+		// relative targets are resolved against the directory of the link
+		if filepath.IsAbs(target) {
+			dest = sep
+		}
+		elems, i = append(strings.Split(filepath.ToSlash(target), "/"), elems[i+1:]...), -1
+	}
+	return dest, nil
+}
+
+// errOf returns the underlying error of an *os.PathError
+func errOf(err error) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return pe.Err
+	}
+	return err
+}
+
+// linkInfo is the os.FileInfo of a symbolic link
+type linkInfo struct {
+	os.FileInfo
+	target string
+}
+
+func (i *linkInfo) Mode() os.FileMode { return os.ModeSymlink | 0777 }
+func (i *linkInfo) Size() int64       { return int64(len(i.target)) }
+func (i *linkInfo) IsDir() bool       { return false }
+
+// file reports the symbolic links of a directory in Readdir
+type file struct {
+	afero.File
+	fs   *SymlinkFs
+	path string
+}
+
+func (f *file) Readdir(count int) ([]os.FileInfo, error) {
+	infos, err := f.File.Readdir(count)
+	for i, fi := range infos {
+		if target, ok := f.fs.link(filepath.Join(f.path, fi.Name())); ok {
+			infos[i] = &linkInfo{FileInfo: fi, target: target}
+		}
+	}
+	return infos, err
+}
@@ -6,7 +6,9 @@ import (
 	"sync"
 
 	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
 
+	"github.com/alex-held/devctl-kit/pkg/config"
 	"github.com/alex-held/devctl-kit/pkg/log"
 	"github.com/alex-held/devctl-kit/pkg/system"
 
@@ -23,10 +25,19 @@ type factory struct {
 	//	openAPIParser *openapi.CachedOpenAPIParser
 	//	openAPIGetter *openapi.CachedOpenAPIGetter
 	//	parser        sync.Once
-	getter  sync.Once
-	streams cli.IOStreams
-	fs      afero.Fs
-	paths   Paths
+
+	// getter resolves the default Paths and the profile on first use
+	getter       sync.Once
+	streams      cli.IOStreams
+	fs           afero.Fs
+	paths        Paths
+	profileFlags *pflag.FlagSet
+
+	// loader loads the Config on first use
+	loader     sync.Once
+	configOpts []config.LoaderOption
+	config     *config.Config
+	configErr  error
 }
 
 func (f *factory) RuntimeInfo() system.RuntimeInfo {
@@ -45,9 +56,28 @@ func (f *factory) Logger() log.Logger {
 	return f.logger
 }
 func (f *factory) Paths() Paths {
+	f.getter.Do(func() {
+		if f.paths.Base() == "" {
+			f.paths = MustGetPaths()
+		}
+		if f.profileFlags == nil {
+			return
+		}
+		if flag := f.profileFlags.Lookup(ProfileFlag); flag != nil && flag.Changed {
+			f.paths = f.paths.WithProfile(flag.Value.String())
+		}
+	})
 	return f.paths
 }
 
+func (f *factory) Config() (*config.Config, error) {
+	f.loader.Do(func() {
+		opts := append([]config.LoaderOption{config.WithProfile(f.Paths().Profile())}, f.configOpts...)
+		f.config, f.configErr = config.NewLoader(f.Fs(), f.Paths().Config(), opts...).Load()
+	})
+	return f.config, f.configErr
+}
+
 func (f *factory) Streams() cli.IOStreams {
 	return f.streams
 }
@@ -73,6 +103,10 @@ type Factory interface {
 
 	Streams() cli.IOStreams
 
+	// Config returns the configuration loaded from the defaults, the global and project
+	// configuration files, environment variables and flags
+	Config() (*config.Config, error)
+
 	// Returns a schema that can validate objects stored on disk.
 	Validator(validate bool) (validation.Schema, error)
 
@@ -83,11 +117,14 @@ type Factory interface {
 }
 
 type FactoryConfig struct {
+	// Paths defaults to MustGetPaths, which is called on first use
 	Paths             Paths
 	LoggerConfig      *log.Config
 	Streams           *cli.IOStreams
 	RuntimeInfoGetter system.RuntimeInfoGetter
 	Fs                afero.Fs
+	ConfigOptions     []config.LoaderOption
+	ProfileFlags      *pflag.FlagSet
 }
 
 type FactoryOption func(*FactoryConfig) *FactoryConfig
@@ -103,12 +140,71 @@ func WithIO(in io.Reader, out, err io.Writer) FactoryOption {
 	}
 }
 
+// WithStreams sets the IOStreams of the Factory
+func WithStreams(streams cli.IOStreams) FactoryOption {
+	return func(c *FactoryConfig) *FactoryConfig {
+		c.Streams = &streams
+		return c
+	}
+}
+
+// WithPaths sets the Paths of the Factory instead of inferring them from the environment
+func WithPaths(paths Paths) FactoryOption {
+	return func(c *FactoryConfig) *FactoryConfig {
+		c.Paths = paths
+		return c
+	}
+}
+
+// WithFs sets the afero.Fs of the Factory
+func WithFs(fs afero.Fs) FactoryOption {
+	return func(c *FactoryConfig) *FactoryConfig {
+		c.Fs = fs
+		return c
+	}
+}
+
+// WithRuntimeInfoGetter sets the system.RuntimeInfoGetter of the Factory
+func WithRuntimeInfoGetter(getter system.RuntimeInfoGetter) FactoryOption {
+	return func(c *FactoryConfig) *FactoryConfig {
+		c.RuntimeInfoGetter = getter
+		return c
+	}
+}
+
+// WithLoggerConfig sets the log.Config of the Factory's Logger
+func WithLoggerConfig(cfg *log.Config) FactoryOption {
+	return func(c *FactoryConfig) *FactoryConfig {
+		c.LoggerConfig = cfg
+		return c
+	}
+}
+
+// WithConfigOptions adds options of the config.Loader, e.g. config.WithFlags
+func WithConfigOptions(opts ...config.LoaderOption) FactoryOption {
+	return func(c *FactoryConfig) *FactoryConfig {
+		c.ConfigOptions = append(c.ConfigOptions, opts...)
+		return c
+	}
+}
+
+// WithProfileFlags selects the profile by the flag registered by AddProfileFlag, if it is
+// changed when the Paths are used first
+func WithProfileFlags(flags *pflag.FlagSet) FactoryOption {
+	return func(c *FactoryConfig) *FactoryConfig {
+		c.ProfileFlags = flags
+		return c
+	}
+}
+
+// NewFactory returns a Factory configured by opts. Unless set by an option, Paths are
+// inferred from the environment when they are used first, so that creating a Factory
+// never fails.
 func NewFactory(opts ...FactoryOption) Factory {
 	cfg := &FactoryConfig{
 		LoggerConfig:      &log.DefaultConfig,
 		Fs:                afero.NewOsFs(),
 		RuntimeInfoGetter: system.OSRuntimeInfoGetter{},
-		Paths:             MustGetPaths(),
 	}
 	defaults := []FactoryOption{
 		WithIO(os.Stdin, os.Stdout, os.Stdout),
@@ -125,5 +221,7 @@ func NewFactory(opts ...FactoryOption) Factory {
 		getter:            sync.Once{},
 		streams:           *cfg.Streams,
 		fs:                cfg.Fs,
+		configOpts:        cfg.ConfigOptions,
+		profileFlags:      cfg.ProfileFlags,
 	}
 }
@@ -0,0 +1,27 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+func TestNewFactory_Paths(t *testing.T) {
+	t.Setenv("DEVCTL_ROOT", "/from/env")
+	f := env.NewFactory()
+
+	// paths are inferred on first use, not by NewFactory
+	t.Setenv("DEVCTL_ROOT", "/changed")
+	assert.Equal(t, "/changed", f.Paths().Base())
+
+	t.Setenv("DEVCTL_ROOT", "/changed/again")
+	assert.Equal(t, "/changed", f.Paths().Base())
+}
+
+func TestNewFactory_WithPaths(t *testing.T) {
+	t.Setenv("DEVCTL_ROOT", "/from/env")
+	f := env.NewFactory(env.WithPaths(env.NewPaths("/explicit")))
+	assert.Equal(t, "/explicit", f.Paths().Base())
+}
@@ -1,6 +1,9 @@
 package env
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/spf13/afero"
 )
 
@@ -27,3 +30,37 @@ func IsMemMapFs(f afero.Fs) bool {
 func GetFs() afero.Fs {
 	return fs
 }
+
+// WriteFileAtomic writes data to a temporary file in the directory of path and renames
+// it to path, so that readers either see the old or the new content.
+func WriteFileAtomic(fs afero.Fs, path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	if err = fs.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := afero.TempFile(fs, dir, "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = fs.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = fs.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return fs.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,248 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+)
+
+// LayoutVersion is the version of the directory layout written by EnsureLayout
+const LayoutVersion = 1
+
+// LayoutMarker is the name of the file in the base directory containing the LayoutVersion
+const LayoutMarker = ".layout-version"
+
+// ProblemKind describes what Verify found wrong with the layout
+type ProblemKind string
+
+const (
+	// ProblemMissing is a missing directory or layout marker
+	ProblemMissing ProblemKind = "missing"
+	// ProblemPermissions is a directory with unexpected permissions
+	ProblemPermissions ProblemKind = "permissions"
+	// ProblemVersion is a layout marker with an unsupported version
+	ProblemVersion ProblemKind = "version"
+	// ProblemDanglingLink is a symbolic link in BinPath whose target does not exist
+	ProblemDanglingLink ProblemKind = "dangling"
+	// ProblemOrphanedReceipt is an install receipt without a matching store entry
+	ProblemOrphanedReceipt ProblemKind = "orphaned"
+)
+
+// Problem is a single issue of the layout found by Verify
+type Problem struct {
+	Kind    ProblemKind
+	Path    string
+	Message string
+}
+
+// LayoutReport lists the problems found by Verify, sorted by path
+type LayoutReport struct {
+	Problems []Problem
+}
+
+// String returns a line per problem
+func (r *LayoutReport) String() string {
+	sb := &strings.Builder{}
+	for _, p := range r.Problems {
+		fmt.Fprintf(sb, "%-11s %s (%s)\n", p.Kind, p.Path, p.Message)
+	}
+	return sb.String()
+}
+
+type layoutDir struct {
+	path string
+	perm os.FileMode
+}
+
+// dirs returns the directories of the layout. Configuration and state may contain
+// credentials or logs and are private to the user.
+func (p Paths) dirs() []layoutDir {
+	dirs := []layoutDir{
+		{p.Base(), 0755},
+		{p.Config(), 0700},
+		{p.IndexBase(), 0755},
+		{p.SDK(), 0755},
+		{p.InstallPath(), 0755},
+		{p.InstallReceiptsPath(), 0755},
+		{p.BinPath(), 0755},
+		{p.Cache(), 0755},
+		{p.State(), 0700},
+		{p.Logs(), 0700},
+	}
+	if p.profile != "" {
+		dirs = append(dirs, layoutDir{p.ProfileConfig(), 0700})
+	}
+	return dirs
+}
+
+// EnsureLayout creates the directories of the layout with their expected permissions
+// and writes the LayoutMarker. It fails if the layout was written by a newer version.
+func (p Paths) EnsureLayout(fs afero.Fs) error {
+	marker := filepath.Join(p.Base(), LayoutMarker)
+	if version, err := readLayoutVersion(fs, marker); err == nil && version > LayoutVersion {
+		return errors.Errorf("layout version %d of %s is newer than the supported version %d", version, p.Base(), LayoutVersion)
+	}
+
+	for _, dir := range p.dirs() {
+		if err := fs.MkdirAll(dir.path, dir.perm); err != nil {
+			return errors.Wrapf(err, "failed to create %s", dir.path)
+		}
+		fi, err := fs.Stat(dir.path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to stat %s", dir.path)
+		}
+		if fi.Mode().Perm() != dir.perm {
+			if err = fs.Chmod(dir.path, dir.perm); err != nil {
+				return errors.Wrapf(err, "failed to change the permissions of %s", dir.path)
+			}
+		}
+	}
+
+	if version, err := readLayoutVersion(fs, marker); err == nil && version == LayoutVersion {
+		return nil
+	}
+	return WriteFileAtomic(fs, marker, []byte(strconv.Itoa(LayoutVersion)+"\n"), 0644)
+}
+
+// Verify checks the layout for missing directories, unexpected permissions, dangling
+// symbolic links in BinPath and install receipts without a matching store entry.
+// An *constants.ExitError with constants.IssuesFound is returned if it found problems.
+func (p Paths) Verify(fs afero.Fs) (*LayoutReport, error) {
+	report := &LayoutReport{}
+	add := func(kind ProblemKind, path, format string, args ...interface{}) {
+		report.Problems = append(report.Problems, Problem{Kind: kind, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, dir := range p.dirs() {
+		fi, err := fs.Stat(dir.path)
+		switch {
+		case os.IsNotExist(err):
+			add(ProblemMissing, dir.path, "directory does not exist")
+		case err != nil:
+			return nil, errors.Wrapf(err, "failed to stat %s", dir.path)
+		case !fi.IsDir():
+			add(ProblemMissing, dir.path, "not a directory")
+		case fi.Mode().Perm() != dir.perm:
+			add(ProblemPermissions, dir.path, "mode is %v, expected %v", fi.Mode().Perm(), dir.perm)
+		}
+	}
+
+	marker := filepath.Join(p.Base(), LayoutMarker)
+	switch version, err := readLayoutVersion(fs, marker); {
+	case os.IsNotExist(errors.Cause(err)):
+		add(ProblemMissing, marker, "layout marker does not exist")
+	case err != nil:
+		add(ProblemVersion, marker, "%v", err)
+	case version != LayoutVersion:
+		add(ProblemVersion, marker, "layout version is %d, expected %d", version, LayoutVersion)
+	}
+
+	if err := p.verifyBin(fs, add); err != nil {
+		return nil, err
+	}
+	if err := p.verifyReceipts(fs, add); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool { return report.Problems[i].Path < report.Problems[j].Path })
+	if len(report.Problems) > 0 {
+		return report, &constants.ExitError{
+			ExitCode: constants.IssuesFound,
+			Message:  fmt.Sprintf("%d problems found in the layout of %s", len(report.Problems), p.Base()),
+		}
+	}
+	return report, nil
+}
+
+func (p Paths) verifyBin(fs afero.Fs, add func(ProblemKind, string, string, ...interface{})) error {
+	infos, err := afero.ReadDir(fs, p.BinPath())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to read %s", p.BinPath())
+	}
+	for _, fi := range infos {
+		if fi.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		path := filepath.Join(p.BinPath(), fi.Name())
+		exists, err := Exists(fs, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve %s", path)
+		}
+		if !exists {
+			target, _ := Readlink(fs, path)
+			add(ProblemDanglingLink, path, "target %s does not exist", target)
+		}
+	}
+	return nil
+}
+
+func (p Paths) verifyReceipts(fs afero.Fs, add func(ProblemKind, string, string, ...interface{})) error {
+	infos, err := afero.ReadDir(fs, p.InstallReceiptsPath())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to read %s", p.InstallReceiptsPath())
+	}
+	for _, fi := range infos {
+		if fi.IsDir() && !strings.HasPrefix(fi.Name(), ".") {
+			if err = p.verifyVersionReceipts(fs, fi.Name(), add); err != nil {
+				return err
+			}
+			continue
+		}
+		if fi.IsDir() || filepath.Ext(fi.Name()) != constants.ManifestExtension {
+			continue
+		}
+		plugin := strings.TrimSuffix(fi.Name(), constants.ManifestExtension)
+		if exists, err := Exists(fs, p.PluginInstallPath(plugin)); err != nil {
+			return errors.Wrapf(err, "failed to resolve %s", p.PluginInstallPath(plugin))
+		} else if !exists {
+			add(ProblemOrphanedReceipt, p.PluginInstallReceiptPath(plugin), "%s does not exist", p.PluginInstallPath(plugin))
+		}
+	}
+	return nil
+}
+
+// verifyVersionReceipts reports the receipts of versions of plugin which are not installed
+func (p Paths) verifyVersionReceipts(fs afero.Fs, plugin string, add func(ProblemKind, string, string, ...interface{})) error {
+	dir := filepath.Join(p.InstallReceiptsPath(), plugin)
+	infos, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", dir)
+	}
+	for _, fi := range infos {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || filepath.Ext(fi.Name()) != constants.ManifestExtension {
+			continue
+		}
+		version := strings.TrimSuffix(fi.Name(), constants.ManifestExtension)
+		path := p.PluginVersionInstallPath(plugin, version)
+		if exists, err := Exists(fs, path); err != nil {
+			return errors.Wrapf(err, "failed to resolve %s", path)
+		} else if !exists {
+			add(ProblemOrphanedReceipt, p.PluginVersionInstallReceiptPath(plugin, version), "%s does not exist", path)
+		}
+	}
+	return nil
+}
+
+func readLayoutVersion(fs afero.Fs, marker string) (int, error) {
+	b, err := afero.ReadFile(fs, marker)
+	if err != nil {
+		return 0, err
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, errors.Errorf("invalid layout version %q", strings.TrimSpace(string(b)))
+	}
+	return version, nil
+}
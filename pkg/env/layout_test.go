@@ -0,0 +1,83 @@
+package env_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+)
+
+func TestEnsureLayout(t *testing.T) {
+	fs := envtest.NewSymlinkFs()
+	p := env.NewPaths("/sandbox/.devctl")
+
+	require.NoError(t, p.EnsureLayout(fs))
+	for _, dir := range []string{p.Config(), p.IndexBase(), p.SDK(), p.InstallPath(), p.InstallReceiptsPath(), p.BinPath(), p.Logs()} {
+		exists, err := afero.DirExists(fs, dir)
+		require.NoError(t, err)
+		assert.True(t, exists, dir)
+	}
+	fi, err := fs.Stat(p.Config())
+	require.NoError(t, err)
+	assert.Equal(t, "-rwx------", fi.Mode().Perm().String())
+
+	b, err := afero.ReadFile(fs, filepath.Join(p.Base(), env.LayoutMarker))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n", string(b))
+
+	report, err := p.Verify(fs)
+	require.NoError(t, err)
+	assert.Empty(t, report.Problems)
+
+	// EnsureLayout is idempotent and repairs permissions
+	require.NoError(t, fs.Chmod(p.BinPath(), 0777))
+	require.NoError(t, p.EnsureLayout(fs))
+	_, err = p.Verify(fs)
+	require.NoError(t, err)
+}
+
+func TestEnsureLayout_NewerVersion(t *testing.T) {
+	fs := envtest.NewSymlinkFs()
+	p := env.NewPaths("/sandbox/.devctl")
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(p.Base(), env.LayoutMarker), []byte("2\n"), 0644))
+
+	assert.Error(t, p.EnsureLayout(fs))
+}
+
+func TestVerify(t *testing.T) {
+	fs := envtest.NewSymlinkFs()
+	p := env.NewPaths("/sandbox/.devctl")
+	require.NoError(t, p.EnsureLayout(fs))
+
+	require.NoError(t, fs.RemoveAll(p.SDK()))
+	require.NoError(t, fs.Chmod(p.Config(), 0755))
+	require.NoError(t, fs.MkdirAll(p.PluginVersionInstallPath("go", "1.17"), 0755))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(p.PluginVersionInstallPath("go", "1.17"), "README"), nil, 0644))
+	require.NoError(t, env.Symlink(fs, "../store/go/1.17/bin/go", p.Bin("go")))
+	require.NoError(t, env.Symlink(fs, "../store/node/16/bin/node", p.Bin("node")))
+	require.NoError(t, afero.WriteFile(fs, p.PluginInstallReceiptPath("go"), nil, 0644))
+	require.NoError(t, afero.WriteFile(fs, p.PluginInstallReceiptPath("node"), nil, 0644))
+	require.NoError(t, afero.WriteFile(fs, p.PluginVersionInstallReceiptPath("go", "1.17"), nil, 0644))
+	require.NoError(t, afero.WriteFile(fs, p.PluginVersionInstallReceiptPath("go", "1.16"), nil, 0644))
+
+	report, err := p.Verify(fs)
+	var exitErr *constants.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, constants.IssuesFound, int(exitErr.ExitCode))
+
+	assert.Equal(t, []env.Problem{
+		{Kind: env.ProblemDanglingLink, Path: p.Bin("go"), Message: "target ../store/go/1.17/bin/go does not exist"},
+		{Kind: env.ProblemDanglingLink, Path: p.Bin("node"), Message: "target ../store/node/16/bin/node does not exist"},
+		{Kind: env.ProblemPermissions, Path: p.Config(), Message: "mode is -rwxr-xr-x, expected -rwx------"},
+		{Kind: env.ProblemOrphanedReceipt, Path: p.PluginVersionInstallReceiptPath("go", "1.16"), Message: p.PluginVersionInstallPath("go", "1.16") + " does not exist"},
+		{Kind: env.ProblemOrphanedReceipt, Path: p.PluginInstallReceiptPath("node"), Message: p.PluginInstallPath("node") + " does not exist"},
+		{Kind: env.ProblemMissing, Path: p.SDK(), Message: "directory does not exist"},
+	}, report.Problems)
+	assert.Contains(t, report.String(), "orphaned")
+}
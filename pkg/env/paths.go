@@ -5,58 +5,163 @@ import (
 	"path/filepath"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 
 	"github.com/alex-held/devctl-kit/pkg/log"
 
 	"github.com/alex-held/devctl-kit/pkg/constants"
 )
 
+// Layout determines where Paths places configuration, data, cache and state files
+type Layout string
+
+const (
+	// LayoutLegacy places all files below a single base directory, e.g. $HOME/.devctl
+	LayoutLegacy Layout = "legacy"
+	// LayoutXDG places the files in the devctl directories of the XDG Base Directory
+	// Specification, e.g. $XDG_CONFIG_HOME/devctl and $XDG_DATA_HOME/devctl
+	LayoutXDG Layout = "xdg"
+)
+
 type Paths struct {
-	base string
-	tmp  string
+	layout  Layout
+	profile string
+	// base contains the data, i.e. SDKs, the store, indexes, receipts and binaries.
+	// SDKs, the store, receipts and binaries of a profile are below its data directory.
+	base   string
+	config string
+	cache  string
+	state  string
+	tmp    string
 }
 
-// MustGetPaths returns the inferred paths for devctl. By default, it assumes
-// $HOME/.devctl as the base path, but can be overridden via DEVCTL_ROOT environment
-// variable.
+// MustGetPaths returns the inferred paths for devctl like GetPaths, but panics if they
+// cannot be inferred.
 func MustGetPaths() Paths {
+	p, err := GetPaths()
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// GetPaths returns the inferred paths for devctl. By default, it assumes $HOME/.devctl
+// as the base path, but can be overridden via DEVCTL_ROOT environment variable.
+// DEVCTL_LAYOUT=xdg selects the XDG layout, see GetXDGPaths, unless DEVCTL_ROOT is set.
+// DEVCTL_ENV selects a profile, see Paths.WithProfile.
+func GetPaths() (Paths, error) {
+	p, err := getLayout()
+	if err != nil {
+		return Paths{}, err
+	}
+	if profile := os.Getenv(constants.DEVCTL_ENV_KEY); profile != "" {
+		if err := ValidateProfile(profile); err != nil {
+			return Paths{}, errors.Wrapf(err, "invalid %s", constants.DEVCTL_ENV_KEY)
+		}
+		p = p.WithProfile(profile)
+	}
+	return p, nil
+}
+
+func getLayout() (Paths, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		panic(errors.Wrap(err, "cannot get user home dir"))
+		return Paths{}, errors.Wrap(err, "cannot get user home dir")
 	}
 	base := filepath.Join(homeDir, constants.DefaultDevctlDir)
 	if fromEnv := os.Getenv(constants.DEVCTL_ROOT_KEY); fromEnv != "" {
 		base = fromEnv
 		log.Infof("using environment override %s=%s", constants.DEVCTL_ROOT_KEY, fromEnv)
+	} else {
+		switch layout := Layout(os.Getenv(constants.DEVCTL_LAYOUT_KEY)); layout {
+		case "", LayoutLegacy:
+		case LayoutXDG:
+			return GetXDGPaths(homeDir), nil
+		default:
+			return Paths{}, errors.Errorf("unknown layout %s=%s, expected %s or %s", constants.DEVCTL_LAYOUT_KEY, layout, LayoutLegacy, LayoutXDG)
+		}
 	}
 
 	base, err = filepath.Abs(base)
 	if err != nil {
-		panic(errors.Wrap(err, "cannot get absolute path"))
+		return Paths{}, errors.Wrap(err, "cannot get absolute path")
 	}
-	return NewPaths(base)
+	return NewPaths(base), nil
 }
 
+// NewPaths returns the legacy layout below base
+//
+// e.g. {base}/configs, {base}/cache and {base}/state
 func NewPaths(base string) Paths {
 	return Paths{
-		base: base,
-		tmp:  os.TempDir(),
+		layout: LayoutLegacy,
+		base:   base,
+		config: filepath.Join(base, constants.ConfigDir),
+		cache:  filepath.Join(base, constants.CacheDir),
+		state:  filepath.Join(base, constants.StateDir),
+		tmp:    os.TempDir(),
+	}
+}
+
+// NewXDGPaths returns the XDG layout using the given directories
+func NewXDGPaths(config, data, cache, state string) Paths {
+	return Paths{
+		layout: LayoutXDG,
+		base:   data,
+		config: config,
+		cache:  cache,
+		state:  state,
+		tmp:    os.TempDir(),
 	}
 }
 
-func (p Paths) Config(paths ...string) string { return p.join(constants.ConfigDir, paths...) }
-func (p Paths) SDK(paths ...string) string    { return p.join(constants.SDKsDir, paths...) }
-func (p Paths) Store(paths ...string) string  { return p.join(constants.StoreDir, paths...) }
-func (p Paths) Bin(paths ...string) string    { return p.join(constants.BinDir, paths...) }
+// GetXDGPaths returns the XDG layout inferred from the XDG_*_HOME environment variables.
+// Unset or relative variables default to the directories of the specification below home.
+//
+// e.g. $XDG_CONFIG_HOME/devctl or {home}/.config/devctl
+func GetXDGPaths(home string) Paths {
+	return NewXDGPaths(
+		xdgDir(constants.XDG_CONFIG_HOME_KEY, home, ".config"),
+		xdgDir(constants.XDG_DATA_HOME_KEY, home, ".local", "share"),
+		xdgDir(constants.XDG_CACHE_HOME_KEY, home, ".cache"),
+		xdgDir(constants.XDG_STATE_HOME_KEY, home, ".local", "state"),
+	)
+}
+
+func xdgDir(key, home string, fallback ...string) string {
+	dir := os.Getenv(key)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(append([]string{home}, fallback...)...)
+	}
+	return filepath.Join(dir, constants.XDGDir)
+}
+
+func (p Paths) Config(paths ...string) string { return under(p.config, paths...) }
+func (p Paths) SDK(paths ...string) string    { return under(p.data(constants.SDKsDir), paths...) }
+func (p Paths) Store(paths ...string) string  { return under(p.data(constants.StoreDir), paths...) }
+func (p Paths) Bin(paths ...string) string    { return under(p.data(constants.BinDir), paths...) }
+func (p Paths) Cache(paths ...string) string  { return under(p.cache, paths...) }
+func (p Paths) State(paths ...string) string  { return under(p.state, paths...) }
+func (p Paths) Logs(paths ...string) string   { return under(p.State(constants.LogsDir), paths...) }
 
 func (p Paths) Subdir(paths ...string) string { return p.join("", paths...) }
+func (p Paths) data(dir string) string {
+	if p.profile == "" {
+		return filepath.Join(p.base, dir)
+	}
+	return filepath.Join(p.base, constants.ProfilesDir, p.profile, dir)
+}
 func (p Paths) join(dir string, paths ...string) string {
 	return filepath.Join(p.base, dir, filepath.Join(paths...))
 }
+func under(dir string, paths ...string) string { return filepath.Join(dir, filepath.Join(paths...)) }
 
-// Base returns the devctl base directory
+// Base returns the devctl base directory, i.e. the data directory of the XDG layout
 func (p Paths) Base() string { return p.base }
 
+// Layout returns the Layout of the paths
+func (p Paths) Layout() Layout { return p.layout }
+
 // IndexBase returns the devctl index directory
 func (p Paths) IndexBase() string { return filepath.Join(p.base, constants.IndexDir) }
 
@@ -84,18 +189,36 @@ func (p Paths) IndexPluginManifestPath(indexName, pluginName string) string {
 // InstallReceiptsPath returns the base directory where plugin receipts are stored.
 //
 // e.g. {BasePath}/receipts
-func (p Paths) InstallReceiptsPath() string { return filepath.Join(p.base, "receipts") }
+func (p Paths) InstallReceiptsPath() string { return p.data(constants.ReceiptsDir) }
 
 // BinPath returns the path where plugin executable symbolic links are found.
 // This path should be added to $PATH in client machine.
 //
 // e.g. {BasePath}/bin
-func (p Paths) BinPath() string { return filepath.Join(p.base, constants.BinDir) }
+func (p Paths) BinPath() string { return p.data(constants.BinDir) }
 
 // InstallPath returns the base directory for plugin installations.
 //
 // e.g. {BasePath}/store
-func (p Paths) InstallPath() string { return filepath.Join(p.base, constants.StoreDir) }
+func (p Paths) InstallPath() string { return p.data(constants.StoreDir) }
+
+// BlobPath returns the content-addressed store of downloaded artifacts. It is shared
+// by the profiles and kept in the store of the default profile.
+//
+// e.g. {Base}/store/.blobs/sha256
+func (p Paths) BlobPath() string {
+	return filepath.Join(p.base, constants.StoreDir, constants.BlobsDir, "sha256")
+}
+
+// Blob returns the path of the blob with the hex encoded sha256 sum.
+//
+// e.g. {BlobPath}/ab/ab12...
+func (p Paths) Blob(sum string) string {
+	if len(sum) < 2 {
+		return filepath.Join(p.BlobPath(), sum)
+	}
+	return filepath.Join(p.BlobPath(), sum[:2], sum)
+}
 
 // PluginInstallPath returns the path to install the plugin.
 //
@@ -104,6 +227,14 @@ func (p Paths) PluginInstallPath(plugin string) string {
 	return filepath.Join(p.InstallPath(), plugin)
 }
 
+// PluginCurrentPath returns the symbolic link to the install directory of the active
+// version of plugin.
+//
+// e.g. {InstallPath}/{plugin}/current
+func (p Paths) PluginCurrentPath(plugin string) string {
+	return filepath.Join(p.PluginInstallPath(plugin), constants.CurrentLink)
+}
+
 // PluginInstallReceiptPath returns the path to the install receipt for plugin.
 //
 // e.g. {InstallReceiptsPath}/{plugin}.yaml
@@ -111,6 +242,14 @@ func (p Paths) PluginInstallReceiptPath(plugin string) string {
 	return filepath.Join(p.InstallReceiptsPath(), plugin+constants.ManifestExtension)
 }
 
+// PluginVersionInstallReceiptPath returns the path to the install receipt of the
+// specified version of plugin.
+//
+// e.g. {InstallReceiptsPath}/{plugin}/{version}.yaml
+func (p Paths) PluginVersionInstallReceiptPath(plugin, version string) string {
+	return filepath.Join(p.InstallReceiptsPath(), plugin, version+constants.ManifestExtension)
+}
+
 // PluginVersionInstallPath returns the path to the specified version of specified
 // plugin.
 //
@@ -119,25 +258,18 @@ func (p Paths) PluginVersionInstallPath(plugin, version string) string {
 	return filepath.Join(p.InstallPath(), plugin, version)
 }
 
-// Realpath evaluates symbolic links. If the path is not a symbolic link, it
-// returns the cleaned path. Symbolic links with relative paths return error.
+// Realpath evaluates the symbolic links of path using the default afero.Fs, see RealpathFs.
 func Realpath(path string) (string, error) {
-	if !IsOsFs(fs) {
-		panic("realpath is only supported for afero.OsFs")
-	}
+	return RealpathFs(GetFs(), path)
+}
 
-	s, err := os.Lstat(path)
+// RealpathFs evaluates all symbolic links of path using fs and returns the cleaned
+// path. Relative symbolic links are resolved against the directory of the link.
+// The path must exist.
+func RealpathFs(fs afero.Fs, path string) (string, error) {
+	realpath, err := EvalSymlinks(fs, path)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to stat the currently executed path (%q)", path)
-	}
-
-	if s.Mode()&os.ModeSymlink != 0 {
-		if path, err = os.Readlink(path); err != nil {
-			return "", errors.Wrap(err, "failed to resolve the symlink of the currently executed version")
-		}
-		if !filepath.IsAbs(path) {
-			return "", errors.Errorf("symbolic link is relative (%s)", path)
-		}
+		return "", errors.Wrapf(err, "failed to evaluate the symbolic links of %q", path)
 	}
-	return filepath.Clean(path), nil
+	return realpath, nil
 }
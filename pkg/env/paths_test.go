@@ -0,0 +1,56 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+func TestNewPaths(t *testing.T) {
+	p := env.NewPaths("/home/user/.devctl")
+
+	assert.Equal(t, env.LayoutLegacy, p.Layout())
+	assert.Equal(t, "/home/user/.devctl/configs/config.yaml", p.Config("config.yaml"))
+	assert.Equal(t, "/home/user/.devctl/store/go", p.Store("go"))
+	assert.Equal(t, "/home/user/.devctl/cache/downloads", p.Cache("downloads"))
+	assert.Equal(t, "/home/user/.devctl/state", p.State())
+	assert.Equal(t, "/home/user/.devctl/state/logs/devctl.log", p.Logs("devctl.log"))
+}
+
+func TestMustGetPaths_XDG(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("DEVCTL_ROOT", "")
+	t.Setenv("DEVCTL_LAYOUT", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_DATA_HOME", "/xdg/data")
+	t.Setenv("XDG_CACHE_HOME", "relative/is/ignored")
+	t.Setenv("XDG_STATE_HOME", "")
+
+	p := env.MustGetPaths()
+
+	assert.Equal(t, env.LayoutXDG, p.Layout())
+	assert.Equal(t, "/xdg/config/devctl/config.yaml", p.Config("config.yaml"))
+	assert.Equal(t, "/xdg/data/devctl", p.Base())
+	assert.Equal(t, "/xdg/data/devctl/sdks/go", p.SDK("go"))
+	assert.Equal(t, "/xdg/data/devctl/bin", p.BinPath())
+	assert.Equal(t, "/home/user/.cache/devctl", p.Cache())
+	assert.Equal(t, "/home/user/.local/state/devctl/logs", p.Logs())
+}
+
+func TestMustGetPaths_Legacy(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("DEVCTL_ROOT", "")
+	t.Setenv("DEVCTL_LAYOUT", "")
+	assert.Equal(t, env.NewPaths("/home/user/.devctl"), env.MustGetPaths())
+
+	// DEVCTL_ROOT takes precedence over the layout
+	t.Setenv("DEVCTL_ROOT", "/opt/devctl")
+	t.Setenv("DEVCTL_LAYOUT", "xdg")
+	assert.Equal(t, env.NewPaths("/opt/devctl"), env.MustGetPaths())
+
+	t.Setenv("DEVCTL_ROOT", "")
+	t.Setenv("DEVCTL_LAYOUT", "unknown")
+	assert.Panics(t, func() { env.MustGetPaths() })
+}
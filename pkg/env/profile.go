@@ -0,0 +1,120 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+)
+
+// ProfileFlag is the name of the flag selecting the profile, see AddProfileFlag
+const ProfileFlag = "env"
+
+var profileRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateProfile checks that name is a valid profile name, e.g. work or ci
+func ValidateProfile(name string) error {
+	if !profileRe.MatchString(name) {
+		return errors.Errorf("invalid profile name %q, expected lower case letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// WithProfile returns the paths of the profile name. The SDKs, the store, install
+// receipts and binaries of a profile are isolated below {Base}/profiles/{name}, its
+// configuration is read from {Config}/profiles/{name}. Indexes, the cache and the
+// state are shared. The constants.DefaultProfile uses the paths without profile.
+func (p Paths) WithProfile(name string) Paths {
+	if name == constants.DefaultProfile {
+		name = ""
+	}
+	p.profile = name
+	return p
+}
+
+// Profile returns the name of the profile, i.e. constants.DefaultProfile if none is selected
+func (p Paths) Profile() string {
+	if p.profile == "" {
+		return constants.DefaultProfile
+	}
+	return p.profile
+}
+
+// ProfileConfig returns the configuration directory of the profile
+//
+// e.g. {Config}/profiles/{profile}
+func (p Paths) ProfileConfig(paths ...string) string {
+	return p.Config(constants.ProfilesDir, p.Profile(), filepath.Join(paths...))
+}
+
+// BinPaths returns the directories containing the binaries of the profile in order of
+// precedence, i.e. the binaries of a profile overlay the shared ones.
+// These paths should be added to $PATH in client machine.
+func (p Paths) BinPaths() []string {
+	if p.profile == "" {
+		return []string{p.BinPath()}
+	}
+	return []string{p.BinPath(), p.WithProfile(constants.DefaultProfile).BinPath()}
+}
+
+// Profiles returns the sorted names of the profiles, including constants.DefaultProfile
+func (p Paths) Profiles(fs afero.Fs) ([]string, error) {
+	profiles := []string{constants.DefaultProfile}
+	infos, err := afero.ReadDir(fs, p.Config(constants.ProfilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to list the profiles in %s", p.Config(constants.ProfilesDir))
+	}
+	for _, fi := range infos {
+		if fi.IsDir() && fi.Name() != constants.DefaultProfile && ValidateProfile(fi.Name()) == nil {
+			profiles = append(profiles, fi.Name())
+		}
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// CreateProfile creates the configuration directory and the layout of the profile name
+func (p Paths) CreateProfile(fs afero.Fs, name string) (Paths, error) {
+	if err := ValidateProfile(name); err != nil {
+		return Paths{}, err
+	}
+	if name == constants.DefaultProfile {
+		return Paths{}, errors.Errorf("profile %s already exists", name)
+	}
+	profile := p.WithProfile(name)
+	if exists, err := afero.DirExists(fs, profile.ProfileConfig()); err != nil {
+		return Paths{}, err
+	} else if exists {
+		return Paths{}, errors.Errorf("profile %s already exists", name)
+	}
+	if err := fs.MkdirAll(profile.ProfileConfig(), 0700); err != nil {
+		return Paths{}, errors.Wrapf(err, "failed to create %s", profile.ProfileConfig())
+	}
+	return profile, profile.EnsureLayout(fs)
+}
+
+// AddProfileFlag registers the flag selecting the profile, which overrides DEVCTL_ENV
+func AddProfileFlag(flags *pflag.FlagSet) {
+	if flags.Lookup(ProfileFlag) == nil {
+		flags.Var(new(profileValue), ProfileFlag, "Name of the profile, overrides "+constants.DEVCTL_ENV_KEY)
+	}
+}
+
+// profileValue is a pflag.Value accepting valid profile names
+type profileValue string
+
+func (v *profileValue) String() string { return string(*v) }
+func (v *profileValue) Type() string   { return "string" }
+func (v *profileValue) Set(name string) error {
+	if err := ValidateProfile(name); err != nil {
+		return err
+	}
+	*v = profileValue(name)
+	return nil
+}
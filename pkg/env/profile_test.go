@@ -0,0 +1,80 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+)
+
+func TestPaths_WithProfile(t *testing.T) {
+	p := env.NewPaths("/home/user/.devctl")
+	work := p.WithProfile("work")
+
+	assert.Equal(t, "default", p.Profile())
+	assert.Equal(t, "work", work.Profile())
+	assert.Equal(t, p, work.WithProfile("default"))
+
+	assert.Equal(t, "/home/user/.devctl/profiles/work/store/go", work.PluginInstallPath("go"))
+	assert.Equal(t, "/home/user/.devctl/profiles/work/receipts/go.yaml", work.PluginInstallReceiptPath("go"))
+	assert.Equal(t, "/home/user/.devctl/profiles/work/sdks", work.SDK())
+	assert.Equal(t, "/home/user/.devctl/configs/profiles/work/config.yaml", work.ProfileConfig("config.yaml"))
+	assert.Equal(t, []string{"/home/user/.devctl/profiles/work/bin", "/home/user/.devctl/bin"}, work.BinPaths())
+	assert.Equal(t, []string{"/home/user/.devctl/bin"}, p.BinPaths())
+
+	// indexes, configuration, cache and state are shared
+	assert.Equal(t, p.IndexPath("default"), work.IndexPath("default"))
+	assert.Equal(t, p.Config(), work.Config())
+	assert.Equal(t, p.Cache(), work.Cache())
+}
+
+func TestPaths_Profiles(t *testing.T) {
+	fs := envtest.NewSymlinkFs()
+	p := env.NewPaths("/sandbox/.devctl")
+
+	profiles, err := p.Profiles(fs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default"}, profiles)
+
+	work, err := p.CreateProfile(fs, "work")
+	require.NoError(t, err)
+	_, err = p.CreateProfile(fs, "ci")
+	require.NoError(t, err)
+	_, err = work.Verify(fs)
+	require.NoError(t, err)
+
+	profiles, err = p.Profiles(fs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci", "default", "work"}, profiles)
+
+	_, err = p.CreateProfile(fs, "work")
+	assert.EqualError(t, err, "profile work already exists")
+	_, err = p.CreateProfile(fs, "default")
+	assert.Error(t, err)
+	_, err = p.CreateProfile(fs, "../escape")
+	assert.Error(t, err)
+}
+
+func TestMustGetPaths_Profile(t *testing.T) {
+	t.Setenv("DEVCTL_ROOT", "/opt/devctl")
+	t.Setenv("DEVCTL_ENV", "ci")
+	assert.Equal(t, env.NewPaths("/opt/devctl").WithProfile("ci"), env.MustGetPaths())
+
+	t.Setenv("DEVCTL_ENV", "Not Valid")
+	assert.Panics(t, func() { env.MustGetPaths() })
+}
+
+func TestNewFactory_ProfileFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	env.AddProfileFlag(flags)
+	f := env.NewFactory(env.WithFs(afero.NewMemMapFs()), env.WithPaths(env.NewPaths("/sandbox")), env.WithProfileFlags(flags))
+
+	assert.Error(t, flags.Parse([]string{"--env", "Not Valid"}))
+	require.NoError(t, flags.Parse([]string{"--env", "work"}))
+	assert.Equal(t, "work", f.Paths().Profile())
+}
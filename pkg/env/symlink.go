@@ -0,0 +1,155 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// maxLinks is the number of symbolic links EvalSymlinks follows before giving up
+const maxLinks = 255
+
+// Lstat returns the os.FileInfo of path without following a symbolic link, if fs
+// implements afero.Lstater, otherwise it falls back to Stat.
+func Lstat(fs afero.Fs, path string) (os.FileInfo, error) {
+	if l, ok := fs.(afero.Lstater); ok {
+		fi, _, err := l.LstatIfPossible(path)
+		return fi, err
+	}
+	return fs.Stat(path)
+}
+
+// Readlink returns the target of the symbolic link path, if fs implements afero.LinkReader
+func Readlink(fs afero.Fs, path string) (string, error) {
+	if r, ok := fs.(afero.LinkReader); ok {
+		return r.ReadlinkIfPossible(path)
+	}
+	return "", &os.PathError{Op: "readlink", Path: path, Err: afero.ErrNoReadlink}
+}
+
+// Symlink creates newname as a symbolic link to oldname, if fs implements afero.Linker
+func Symlink(fs afero.Fs, oldname, newname string) error {
+	if l, ok := fs.(afero.Linker); ok {
+		return l.SymlinkIfPossible(oldname, newname)
+	}
+	return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: afero.ErrNoSymlink}
+}
+
+// IsSymlink returns true if path is a symbolic link
+func IsSymlink(fs afero.Fs, path string) (bool, error) {
+	fi, err := Lstat(fs, path)
+	if err != nil {
+		return false, err
+	}
+	return fi.Mode()&os.ModeSymlink != 0, nil
+}
+
+// Exists returns true if path exists after evaluating symbolic links, i.e. dangling
+// symbolic links do not exist.
+func Exists(fs afero.Fs, path string) (bool, error) {
+	_, err := EvalSymlinks(fs, path)
+	switch {
+	case err == nil:
+		return true, nil
+	case os.IsNotExist(errors.Cause(err)):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// EvalSymlinks returns the path name after the evaluation of any symbolic links like
+// filepath.EvalSymlinks, but reads the links using fs. Relative links are resolved
+// against the directory of the link. The result is relative if path is relative.
+func EvalSymlinks(fs afero.Fs, path string) (string, error) {
+	volLen := len(filepath.VolumeName(path))
+	if volLen < len(path) && os.IsPathSeparator(path[volLen]) {
+		volLen++
+	}
+	vol := path[:volLen]
+	dest := vol
+	links := 0
+	for start, end := volLen, volLen; start < len(path); start = end {
+		for start < len(path) && os.IsPathSeparator(path[start]) {
+			start++
+		}
+		end = start
+		for end < len(path) && !os.IsPathSeparator(path[end]) {
+			end++
+		}
+
+		switch elem := path[start:end]; {
+		case elem == "":
+			continue
+		case elem == ".":
+			continue
+		case elem == "..":
+			// drop the last element of dest unless it is already a ".."
+			r := lastSeparator(dest, volLen)
+			if r < volLen || dest[r+1:] == ".." {
+				if len(dest) > volLen {
+					dest += string(os.PathSeparator)
+				}
+				dest += ".."
+			} else {
+				dest = dest[:r]
+			}
+			continue
+		}
+
+		if len(dest) > volLen && !os.IsPathSeparator(dest[len(dest)-1]) {
+			dest += string(os.PathSeparator)
+		}
+		dest += path[start:end]
+
+		fi, err := Lstat(fs, dest)
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			if !fi.IsDir() && end < len(path) {
+				return "", &os.PathError{Op: "lstat", Path: dest, Err: syscall.ENOTDIR}
+			}
+			continue
+		}
+
+		if links++; links > maxLinks {
+			return "", errors.Errorf("too many symbolic links in %s", path)
+		}
+		link, err := Readlink(fs, dest)
+		if err != nil {
+			return "", err
+		}
+
+		// continue with the remaining elements appended to the target of the link
+		path = link + path[end:]
+		if v := len(filepath.VolumeName(link)); v > 0 || (len(link) > 0 && os.IsPathSeparator(link[0])) {
+			if v < len(link) && os.IsPathSeparator(link[v]) {
+				v++
+			}
+			vol, volLen = link[:v], v
+			dest, end = vol, v
+		} else {
+			if r := lastSeparator(dest, volLen); r < volLen {
+				dest = vol
+			} else {
+				dest = dest[:r]
+			}
+			end = 0
+		}
+	}
+	return filepath.Clean(dest), nil
+}
+
+func lastSeparator(path string, from int) int {
+	r := len(path) - 1
+	for ; r >= from; r-- {
+		if os.IsPathSeparator(path[r]) {
+			break
+		}
+	}
+	return r
+}
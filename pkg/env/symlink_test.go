@@ -0,0 +1,172 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+)
+
+// fixture creates the same tree of files and symbolic links below root
+func fixture(t *testing.T, fs afero.Fs, root string) {
+	t.Helper()
+	require.NoError(t, fs.MkdirAll(filepath.Join(root, "store", "go", "1.17"), 0755))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(root, "store", "go", "1.17", "go"), []byte("go"), 0755))
+	require.NoError(t, fs.MkdirAll(filepath.Join(root, "bin"), 0755))
+
+	links := map[string]string{
+		"store/go/current": "1.17",
+		"bin/go":           "../store/go/current/go",
+		"bin/abs":          filepath.Join(root, "store", "go", "1.17"),
+		"bin/chain":        "go",
+		"bin/dangling":     "../store/go/1.16",
+		"bin/loop":         "loop",
+	}
+	for link, target := range links {
+		require.NoError(t, env.Symlink(fs, target, filepath.Join(root, link)))
+	}
+}
+
+func fss(t *testing.T) map[string]struct {
+	fs   afero.Fs
+	root string
+} {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	return map[string]struct {
+		fs   afero.Fs
+		root string
+	}{
+		"OsFs":      {afero.NewOsFs(), root},
+		"SymlinkFs": {envtest.NewSymlinkFs(), "/sandbox"},
+	}
+}
+
+func TestEvalSymlinks(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		err  bool
+	}{
+		{path: "store/go/1.17/go", want: "store/go/1.17/go"},
+		{path: "store/go/current", want: "store/go/1.17"},
+		{path: "store/go/current/go", want: "store/go/1.17/go"},
+		{path: "bin/go", want: "store/go/1.17/go"},
+		{path: "bin/abs/go", want: "store/go/1.17/go"},
+		{path: "bin/chain", want: "store/go/1.17/go"},
+		{path: "bin/../store/./go/current", want: "store/go/1.17"},
+		{path: "bin/dangling", err: true},
+		{path: "bin/loop", err: true},
+		{path: "bin/go/nested", err: true},
+	}
+	for name, tc := range fss(t) {
+		fixture(t, tc.fs, tc.root)
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.path, func(t *testing.T) {
+				got, err := env.EvalSymlinks(tc.fs, filepath.Join(tc.root, tt.path))
+				if tt.err {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, filepath.Join(tc.root, tt.want), got)
+
+				if env.IsOsFs(tc.fs) {
+					expected, err := filepath.EvalSymlinks(filepath.Join(tc.root, tt.path))
+					require.NoError(t, err)
+					assert.Equal(t, expected, got)
+				}
+			})
+		}
+	}
+}
+
+func TestSymlinkHelpers(t *testing.T) {
+	for name, tc := range fss(t) {
+		t.Run(name, func(t *testing.T) {
+			fixture(t, tc.fs, tc.root)
+			link := filepath.Join(tc.root, "store", "go", "current")
+
+			target, err := env.Readlink(tc.fs, link)
+			require.NoError(t, err)
+			assert.Equal(t, "1.17", target)
+
+			isLink, err := env.IsSymlink(tc.fs, link)
+			require.NoError(t, err)
+			assert.True(t, isLink)
+
+			fi, err := tc.fs.Stat(link)
+			require.NoError(t, err)
+			assert.True(t, fi.IsDir())
+
+			b, err := afero.ReadFile(tc.fs, filepath.Join(tc.root, "bin", "go"))
+			require.NoError(t, err)
+			assert.Equal(t, "go", string(b))
+
+			exists, err := env.Exists(tc.fs, filepath.Join(tc.root, "bin", "go"))
+			require.NoError(t, err)
+			assert.True(t, exists)
+			exists, err = env.Exists(tc.fs, filepath.Join(tc.root, "bin", "dangling"))
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			err = env.Symlink(tc.fs, "1.17", link)
+			assert.True(t, os.IsExist(err), "got %v", err)
+
+			_, err = env.Readlink(tc.fs, filepath.Join(tc.root, "bin"))
+			assert.Error(t, err)
+
+			infos, err := afero.ReadDir(tc.fs, filepath.Join(tc.root, "store", "go"))
+			require.NoError(t, err)
+			require.Len(t, infos, 2)
+			assert.Equal(t, "1.17", infos[0].Name())
+			assert.True(t, infos[0].IsDir())
+			assert.Equal(t, "current", infos[1].Name())
+			assert.NotZero(t, infos[1].Mode()&os.ModeSymlink)
+
+			require.NoError(t, tc.fs.Remove(link))
+			exists, err = afero.DirExists(tc.fs, filepath.Join(tc.root, "store", "go", "1.17"))
+			require.NoError(t, err)
+			assert.True(t, exists)
+		})
+	}
+}
+
+func TestRenameDir(t *testing.T) {
+	for name, tc := range fss(t) {
+		t.Run(name, func(t *testing.T) {
+			fixture(t, tc.fs, tc.root)
+			store := filepath.Join(tc.root, "store")
+			moved := filepath.Join(tc.root, "moved")
+
+			require.NoError(t, tc.fs.Rename(store, moved))
+			b, err := afero.ReadFile(tc.fs, filepath.Join(moved, "go", "current", "go"))
+			require.NoError(t, err)
+			assert.Equal(t, "go", string(b))
+			exists, err := afero.Exists(tc.fs, store)
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			assert.Error(t, tc.fs.Rename(moved, filepath.Join(tc.root, "bin")), "existing directories are not replaced")
+			assert.Error(t, tc.fs.Rename(moved, filepath.Join(moved, "go", "nested")))
+		})
+	}
+}
+
+func TestRealpathFs(t *testing.T) {
+	fs := envtest.NewSymlinkFs()
+	fixture(t, fs, "/sandbox")
+
+	got, err := env.RealpathFs(fs, "/sandbox/bin/go")
+	require.NoError(t, err)
+	assert.Equal(t, "/sandbox/store/go/1.17/go", got)
+
+	_, err = env.RealpathFs(afero.NewMemMapFs(), "/missing")
+	assert.Error(t, err)
+}
@@ -1,15 +1,14 @@
 package banner
 
 import (
+	"bufio"
 	"bytes"
-	_ "embed"
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"sort"
 	"strings"
-
-	"github.com/common-nighthawk/go-figure"
 )
 
 var (
@@ -18,29 +17,83 @@ var (
 )
 var defaultBannerStrategy = goStrategy
 
-func buildBanner(text string, kind OutputKind) string {
-	fig := figure.NewFigureWithFont(text, getStarwarsFontReader(), true)
-	banner := fig.String()
+// buildBanner returns the framed banner or, if the font cannot be loaded, text as a
+// single comment line
+func buildBanner(text string, kind OutputKind, opts *Options) string {
+	sb := &strings.Builder{}
+	if err := writeBanner(sb, text, kind, opts); err != nil {
+		return fmt.Sprintf(sanitizers[kind], text)
+	}
+	return sb.String()
+}
+
+// writeBanner writes the framed banner to w. Nothing is written if the font cannot be
+// loaded.
+func writeBanner(w io.Writer, text string, kind OutputKind, opts *Options) error {
+	art, err := opts.art(text)
+	if err != nil {
+		return err
+	}
+
+	b := newBuilder(art+footer(opts), kind)
+	b.painter = opts.painter(kind)
+	b.frame = opts.Frame
 
-	b := newBuilder(banner, kind)
+	bw := bufio.NewWriter(w)
+	b.writeTo(bw)
+	return bw.Flush()
+}
 
-	prettified := b.Prettify()
+// figure renders text and drops the blank rows below the baseline
+func figure(font *Font, text string, opts *Options) string {
+	sb := &strings.Builder{}
+	for i, row := range font.Render(text, opts.Layout, opts.Direction) {
+		row = strings.TrimRight(row, " ")
+		if i < font.Baseline || row != "" {
+			sb.WriteString(row)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
 
-	return prettified
+// footer renders the subtitle and metadata below the FIGure, separated by blank lines
+func footer(opts *Options) string {
+	sb := &strings.Builder{}
+	if opts.Subtitle != "" {
+		sb.WriteString("\n" + opts.Subtitle + "\n")
+	}
+	if len(opts.Metadata) > 0 {
+		sb.WriteString("\n")
+		for _, f := range opts.Metadata {
+			sb.WriteString(f.Key + ": " + f.Value + "\n")
+		}
+	}
+	return sb.String()
 }
 
-func GenerateBanner(banner string, kind OutputKind) (out string) {
+// GenerateBanner renders banner as FIGlet art framed by decoration lines.
+// Every line is prefixed with the comment syntax of kind; KIND_TERMINAL banners
+// are left unprefixed and can be colored using WithColor or WithGradient.
+func GenerateBanner(banner string, kind OutputKind, opts ...Option) (out string) {
 	switch defaultBannerStrategy {
 	case curlStrategy:
 		out = generateBanner(http.DefaultClient, banner)
 	case goStrategy:
 		fallthrough
 	default:
-		out = buildBanner(banner, kind)
+		out = buildBanner(banner, kind, newOptions(opts...))
 	}
 	return out
 }
 
+// WriteBanner writes banner rendered like GenerateBanner to w. Use it to write many
+// banners without building intermediate strings. Unlike GenerateBanner it returns the
+// error of a font that cannot be loaded instead of falling back to a comment line.
+func WriteBanner(w io.Writer, banner string, kind OutputKind, opts ...Option) error {
+	return writeBanner(w, banner, kind, newOptions(opts...))
+}
+
 func generateBanner(c *http.Client, banner string) string {
 	defaultBanner := fmt.Sprintf("# %s", banner)
 
@@ -78,18 +131,12 @@ func generateBanner(c *http.Client, banner string) string {
 	return banner[:li+1]
 }
 
-//go:embed fonts/starwars.flf
-var starwarsFont string
-
-func getStarwarsFontReader() io.Reader {
-	return strings.NewReader(starwarsFont)
-}
-
 type bannerBuilder struct {
-	b      *strings.Builder
-	lines  []string
-	banner string
-	kind   OutputKind
+	lines   []string
+	banner  string
+	kind    OutputKind
+	frame   FrameStyle
+	painter *painter
 }
 
 type OutputKind int
@@ -98,46 +145,152 @@ const (
 	KIND_SHELL OutputKind = iota
 	KIND_YAML
 	KIND_GO
+	// KIND_TERMINAL renders the banner without comment prefixes for terminal output
+	KIND_TERMINAL
+	// KIND_SQL uses -- comments (SQL, Lua, Haskell)
+	KIND_SQL
+	// KIND_LISP uses ;; comments
+	KIND_LISP
+	// KIND_CSS wraps every line in /* */
+	KIND_CSS
+	// KIND_HTML wraps every line in <!-- -->
+	KIND_HTML
 )
 
 var (
 	sanitizers = map[OutputKind]string{
-		KIND_SHELL: "# %v\n",
-		KIND_YAML:  "# %v\n",
-		KIND_GO:    "// %v\n",
+		KIND_SHELL:    "# %v\n",
+		KIND_YAML:     "# %v\n",
+		KIND_GO:       "// %v\n",
+		KIND_TERMINAL: "%v\n",
+		KIND_SQL:      "-- %v\n",
+		KIND_LISP:     ";; %v\n",
+		KIND_CSS:      "/* %v */\n",
+		KIND_HTML:     "<!-- %v -->\n",
+	}
+
+	extensionKinds = map[string]OutputKind{
+		".go": KIND_GO, ".c": KIND_GO, ".h": KIND_GO, ".cc": KIND_GO, ".cpp": KIND_GO, ".hpp": KIND_GO,
+		".java": KIND_GO, ".kt": KIND_GO, ".scala": KIND_GO, ".swift": KIND_GO, ".rs": KIND_GO,
+		".js": KIND_GO, ".jsx": KIND_GO, ".ts": KIND_GO, ".tsx": KIND_GO, ".cs": KIND_GO,
+		".proto": KIND_GO, ".dart": KIND_GO, ".groovy": KIND_GO,
+
+		".sh": KIND_SHELL, ".bash": KIND_SHELL, ".zsh": KIND_SHELL, ".fish": KIND_SHELL,
+		".py": KIND_SHELL, ".rb": KIND_SHELL, ".pl": KIND_SHELL, ".ps1": KIND_SHELL, ".nu": KIND_SHELL,
+		".toml": KIND_SHELL, ".tf": KIND_SHELL, ".hcl": KIND_SHELL, ".mk": KIND_SHELL,
+		".dockerfile": KIND_SHELL, ".r": KIND_SHELL, ".cmake": KIND_SHELL,
+
+		".yaml": KIND_YAML, ".yml": KIND_YAML,
+
+		".sql": KIND_SQL, ".lua": KIND_SQL, ".hs": KIND_SQL,
+		".el": KIND_LISP, ".lisp": KIND_LISP, ".clj": KIND_LISP, ".scm": KIND_LISP,
+		".css": KIND_CSS, ".scss": KIND_CSS, ".less": KIND_CSS,
+		".html": KIND_HTML, ".htm": KIND_HTML, ".xml": KIND_HTML, ".svg": KIND_HTML, ".vue": KIND_HTML,
+	}
+
+	fileNameKinds = map[string]OutputKind{
+		"Makefile":    KIND_SHELL,
+		"Dockerfile":  KIND_SHELL,
+		"Vagrantfile": KIND_SHELL,
+		"Gemfile":     KIND_SHELL,
+		".gitignore":  KIND_SHELL,
 	}
 )
 
-type headerLines []string
+// kindNames maps the names used in templates and on the command line to their OutputKind
+var kindNames = map[string]OutputKind{
+	"shell":    KIND_SHELL,
+	"yaml":     KIND_YAML,
+	"go":       KIND_GO,
+	"terminal": KIND_TERMINAL,
+	"sql":      KIND_SQL,
+	"lisp":     KIND_LISP,
+	"css":      KIND_CSS,
+	"html":     KIND_HTML,
+}
 
-func (h headerLines) Len() int {
-	return len(h)
+// ParseKind returns the OutputKind named name, e.g. shell, yaml or go
+func ParseKind(name string) (OutputKind, error) {
+	if kind, ok := kindNames[strings.ToLower(name)]; ok {
+		return kind, nil
+	}
+	return 0, fmt.Errorf("unknown output kind %q", name)
 }
-func (h headerLines) Less(i, j int) bool {
-	return len(h[i]) > len(h[j])
+
+// Kinds returns the names of all OutputKinds, sorted
+func Kinds() (names []string) {
+	for name := range kindNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
-func (h headerLines) Swap(i, j int) {
-	h[i], h[j] = h[j], h[i]
+
+func (k OutputKind) String() string {
+	for name, kind := range kindNames {
+		if kind == k {
+			return name
+		}
+	}
+	return fmt.Sprintf("OutputKind(%d)", int(k))
 }
 
-func (b *bannerBuilder) decorationLine() (line string) {
-	lines := strings.Split(b.sanitizeBanner(), "\n")
+// KindForFile returns the OutputKind matching the comment syntax of the file at path,
+// based on its name or extension
+func KindForFile(path string) (OutputKind, bool) {
+	base := filepath.Base(path)
+	if kind, ok := fileNameKinds[base]; ok {
+		return kind, true
+	}
+	kind, ok := extensionKinds[strings.ToLower(filepath.Ext(base))]
+	return kind, ok
+}
+
+// CommentPrefix returns the part of a comment line of kind preceding the text
+func CommentPrefix(kind OutputKind) string {
+	return strings.SplitN(sanitizers[kind], "%v", 2)[0]
+}
+
+// CommentSuffix returns the part of a comment line of kind following the text, without
+// the trailing newline
+func CommentSuffix(kind OutputKind) string {
+	parts := strings.SplitN(sanitizers[kind], "%v", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.TrimSuffix(parts[1], "\n")
+}
+
+// Comment formats text as a single comment line of kind, without the trailing newline
+func Comment(kind OutputKind, text string) string {
+	return strings.TrimSuffix(fmt.Sprintf(sanitizers[kind], text), "\n")
+}
 
-	l := headerLines(lines)
-	sort.Sort(l)
-	repeatCount := len(l[0]) - 1
+// width returns the display width of the longest commented line, as measured before
+// the banner is painted
+func (b *bannerBuilder) width() (width int) {
+	prefix, suffix := CommentPrefix(b.kind), CommentSuffix(b.kind)
+	for _, line := range b.lines {
+		if n := displayWidth(prefix + line + suffix); n > width {
+			width = n
+		}
+	}
+	return width
+}
 
-	if len(l[0])%2 != 0 {
+func (b *bannerBuilder) decorationLine() (line string) {
+	width := b.width()
+	repeatCount := width - 1
+	if width%2 != 0 {
 		repeatCount++
 	}
 
-	line = b.sanitizeLine(strings.Repeat(" -", repeatCount/2))
+	line = b.sanitizeLine(b.frame.line(repeatCount / 2 * 2))
 	return line
 }
 
 func newBuilder(text string, kind OutputKind) *bannerBuilder {
 	return &bannerBuilder{
-		b:      &strings.Builder{},
 		banner: text,
 		kind:   kind,
 		lines:  strings.Split(text, "\n"),
@@ -149,33 +302,23 @@ func (b *bannerBuilder) sanitizeLine(str string) string {
 	return fmt.Sprintf(s, str)
 }
 
-func (b *bannerBuilder) Prettify() (out string) {
-	line := b.decorationLine()
-
-	if _, err := b.b.WriteString(line); err != nil {
-		return b.sanitizeLine(b.banner)
+// writeTo writes the commented, framed and possibly painted lines to w. Errors are
+// reported by the Flush of w.
+func (b *bannerBuilder) writeTo(w *bufio.Writer) {
+	line := ""
+	if b.frame != FrameNone {
+		line = b.decorationLine()
 	}
+	w.WriteString(line)
 
-	banner := b.sanitizeBanner()
-
-	if _, err := b.b.WriteString(banner); err != nil {
-		return b.sanitizeLine(b.banner)
+	lines := b.lines
+	if b.painter != nil {
+		lines = b.painter.paint(lines)
 	}
-
-	if _, err := b.b.WriteString(line); err != nil {
-		return b.sanitizeLine(b.banner)
+	format := sanitizers[b.kind]
+	for _, l := range lines {
+		fmt.Fprintf(w, format, l)
 	}
 
-	return b.b.String()
-}
-
-func (b *bannerBuilder) sanitizeBanner() string {
-	out := ""
-	for _, line := range b.lines {
-		out += b.sanitizeLine(line)
-	}
-	if out == "" {
-		return b.sanitizeLine(b.banner)
-	}
-	return out
+	w.WriteString(line)
 }
@@ -1,6 +1,7 @@
 package banner_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/alex-held/gold"
@@ -21,3 +22,119 @@ func TestGenerateHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateBanner_Frame(t *testing.T) {
+	for _, name := range banner.FrameStyles() {
+		t.Run(name, func(t *testing.T) {
+			style, err := banner.ParseFrameStyle(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := gold.New(t, goldie.WithTestNameForDir(true), goldie.WithDiffEngine(goldie.ColoredDiff))
+			g.Assert(t, name, []byte(banner.GenerateBanner("Frame", banner.KIND_YAML, banner.WithFrame(style))))
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	old := banner.GenerateBanner("Old", banner.KIND_SHELL)
+	updated := banner.GenerateBanner("New", banner.KIND_SHELL)
+
+	tcs := map[string]struct{ content, expected string }{
+		"insert":           {"echo hi\n", updated + "\necho hi\n"},
+		"insert empty":     {"", updated},
+		"insert shebang":   {"#!/bin/sh\necho hi\n", "#!/bin/sh\n" + updated + "\necho hi\n"},
+		"replace":          {old + "\necho hi\n", updated + "\necho hi\n"},
+		"replace shebang":  {"#!/bin/sh\n" + old + "echo hi\n", "#!/bin/sh\n" + updated + "echo hi\n"},
+		"unclosed comment": {"#  - - - -\n# note\necho hi\n", updated + "\n#  - - - -\n# note\necho hi\n"},
+	}
+	for name, tt := range tcs {
+		t.Run(name, func(t *testing.T) {
+			if actual := banner.Replace(tt.content, updated, banner.KIND_SHELL); actual != tt.expected {
+				t.Errorf("expected:\n%s\nactual:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tcs := []struct {
+		name  string
+		title string
+		kind  banner.OutputKind
+		opts  []banner.Option
+	}{
+		{"shell", "Exports", banner.KIND_SHELL, nil},
+		{"go", "devctl-kit", banner.KIND_GO, []banner.Option{banner.WithFont("slant")}},
+		{"css", "Hello World", banner.KIND_CSS, []banner.Option{banner.WithFont("standard"), banner.WithFrame(banner.FrameDouble)}},
+		{"html", "Hi", banner.KIND_HTML, []banner.Option{banner.WithFont("small"), banner.WithSubtitle("generated docs")}},
+		{"wrapped", "devctl kit", banner.KIND_SQL, []banner.Option{banner.WithWidth(70)}},
+		{"metadata", "ZSH", banner.KIND_SHELL, []banner.Option{
+			banner.WithFont("big"),
+			banner.WithSubtitle("shell init script"),
+			banner.WithMetadata("generator", "devctl shell"),
+			banner.WithMetadata("source", "templates/zshrc.tmpl"),
+		}},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			o := banner.Options{Font: banner.DefaultFont}
+			for _, opt := range tt.opts {
+				opt(&o)
+			}
+			content := "#!/bin/sh\n" + banner.GenerateBanner(tt.title, tt.kind, tt.opts...) + "\nbody\n"
+
+			p, err := banner.Parse(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// starwars has no lowercase letters, so case cannot be recovered
+			if !strings.EqualFold(p.Title, tt.title) || p.Font != o.Font || p.Subtitle != o.Subtitle {
+				t.Errorf("expected %q (%s, %q), got %q (%s, %q)", tt.title, o.Font, o.Subtitle, p.Title, p.Font, p.Subtitle)
+			}
+			expectedKind := tt.kind
+			if expectedKind == banner.KIND_YAML {
+				expectedKind = banner.KIND_SHELL
+			}
+			if p.Kind != expectedKind {
+				t.Errorf("expected kind %v, got %v", expectedKind, p.Kind)
+			}
+			if len(p.Metadata) != len(o.Metadata) {
+				t.Fatalf("expected metadata %v, got %v", o.Metadata, p.Metadata)
+			}
+			for i, f := range o.Metadata {
+				if p.Metadata[i] != f {
+					t.Errorf("expected metadata %v, got %v", f, p.Metadata[i])
+				}
+			}
+			if p.Begin != 1 {
+				t.Errorf("expected banner to begin at line 1, got %d", p.Begin)
+			}
+		})
+	}
+}
+
+func TestParse_Unknown(t *testing.T) {
+	if _, err := banner.Parse("echo hi\n"); err != banner.ErrNoBanner {
+		t.Errorf("expected ErrNoBanner, got %v", err)
+	}
+
+	p, err := banner.Parse("#  - - - - - -\n#  hand made\n#  - - - - - -\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "" || len(p.Art) != 1 {
+		t.Errorf("expected unknown title and art, got %+v", p)
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	content := banner.GenerateBanner("Exports", banner.KIND_SHELL) + "export A=1\n\n" + banner.GenerateBanner("Aliases", banner.KIND_SHELL) + "alias k=kubectl\n"
+	all, err := banner.ParseAll(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Title != "EXPORTS" || all[1].Title != "ALIASES" {
+		t.Errorf("expected Exports and Aliases, got %+v", all)
+	}
+}
@@ -0,0 +1,106 @@
+package banner_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+func TestWriteBanner(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := banner.WriteBanner(buf, "Exports", banner.KIND_GO, banner.WithFont("small")); err != nil {
+		t.Fatal(err)
+	}
+	if expected := banner.GenerateBanner("Exports", banner.KIND_GO, banner.WithFont("small")); buf.String() != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteBanner_UnknownFont(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := banner.WriteBanner(buf, "Exports", banner.KIND_SHELL, banner.WithFont("missing")); err == nil {
+		t.Fatal("expected an error for the unknown font")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+	// the legacy API falls back to a comment line
+	if out := banner.GenerateBanner("Exports", banner.KIND_SHELL, banner.WithFont("missing")); out != "# Exports\n" {
+		t.Errorf("expected a comment line, got %q", out)
+	}
+}
+
+func TestGenerateBanner_Concurrent(t *testing.T) {
+	expected := banner.GenerateBanner("Concurrent", banner.KIND_SHELL)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				banner.GenerateBanner(fmt.Sprintf("title %d", j), banner.KIND_GO, banner.WithFont(banner.Fonts()[i%len(banner.Fonts())]))
+				if actual := banner.GenerateBanner("Concurrent", banner.KIND_SHELL); actual != expected {
+					t.Errorf("expected:\n%s\nactual:\n%s", expected, actual)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkGenerateBanner(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		banner.GenerateBanner("devctl-kit", banner.KIND_SHELL)
+	}
+}
+
+func BenchmarkGenerateBanner_Uncached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		banner.GenerateBanner(fmt.Sprintf("devctl %d", i), banner.KIND_SHELL)
+	}
+}
+
+func BenchmarkWriteBanner(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = banner.WriteBanner(ioutil.Discard, "devctl-kit", banner.KIND_SHELL)
+	}
+}
+
+func BenchmarkWriteBanner_Long(b *testing.B) {
+	text := strings.Repeat("devctl kit ", 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = banner.WriteBanner(ioutil.Discard, text, banner.KIND_GO, banner.WithWidth(120))
+	}
+}
+
+func BenchmarkLoadFont(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := banner.LoadFont(banner.DefaultFont); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseFont(b *testing.B) {
+	data, err := ioutil.ReadFile("fonts/" + banner.DefaultFont + ".flf")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := banner.ParseFont(banner.DefaultFont, bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
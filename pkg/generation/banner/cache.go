@@ -0,0 +1,89 @@
+package banner
+
+import (
+	"sync"
+)
+
+// maxCachedArt bounds the number of cached FIGures, the cache is cleared when it is full
+const maxCachedArt = 4096
+
+var (
+	fontCache = struct {
+		sync.RWMutex
+		fonts map[string]*Font
+	}{fonts: map[string]*Font{}}
+
+	artCache = struct {
+		sync.RWMutex
+		art map[artKey]string
+	}{art: map[artKey]string{}}
+)
+
+// artKey identifies a FIGure by its text and every option affecting the rendering
+type artKey struct {
+	text      string
+	font      *Font
+	layout    Layout
+	direction Direction
+	control   *ControlFile
+	width     int
+}
+
+// cachedFont returns the parsed embedded font name, parsing it on first use
+func cachedFont(name string, parse func(string) (*Font, error)) (*Font, error) {
+	fontCache.RLock()
+	f, ok := fontCache.fonts[name]
+	fontCache.RUnlock()
+	if ok {
+		return f, nil
+	}
+
+	f, err := parse(name)
+	if err != nil {
+		return nil, err
+	}
+	fontCache.Lock()
+	defer fontCache.Unlock()
+	if cached, ok := fontCache.fonts[name]; ok {
+		return cached, nil
+	}
+	fontCache.fonts[name] = f
+	return f, nil
+}
+
+// art returns the FIGure of text, rendering it on first use
+func (o *Options) art(text string) (string, error) {
+	font, err := o.font()
+	if err != nil {
+		return "", err
+	}
+	key := artKey{
+		text:      text,
+		font:      font,
+		layout:    o.Layout,
+		direction: o.Direction,
+		control:   o.Control,
+		width:     o.Width,
+	}
+
+	artCache.RLock()
+	art, ok := artCache.art[key]
+	artCache.RUnlock()
+	if ok {
+		return art, nil
+	}
+
+	text = o.Control.Translate(text)
+	if o.Width > 0 {
+		text = wrap(font, text, o)
+	}
+	art = figure(font, text, o)
+
+	artCache.Lock()
+	defer artCache.Unlock()
+	if len(artCache.art) >= maxCachedArt {
+		artCache.art = map[artKey]string{}
+	}
+	artCache.art[key] = art
+	return art, nil
+}
@@ -0,0 +1,229 @@
+package banner
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Color is a 24 bit RGB color used to paint terminal banners.
+type Color struct {
+	R, G, B uint8
+}
+
+var namedColors = map[string]Color{
+	"black":   {0, 0, 0},
+	"red":     {205, 49, 49},
+	"green":   {13, 188, 121},
+	"yellow":  {229, 229, 16},
+	"blue":    {36, 114, 200},
+	"magenta": {188, 63, 188},
+	"purple":  {188, 63, 188},
+	"cyan":    {17, 168, 205},
+	"gray":    {229, 229, 229},
+	"white":   {255, 255, 255},
+}
+
+// ParseColor parses a color from a hex triplet (e.g. #ff8800 or ff8800) or a named color.
+func ParseColor(s string) (Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// Hex returns the color as a #rrggbb hex triplet
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// lerp interpolates between c and to; t is clamped to [0,1]
+func (c Color) lerp(to Color, t float64) Color {
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	return Color{R: mix(c.R, to.R), G: mix(c.G, to.G), B: mix(c.B, to.B)}
+}
+
+// ColorProfile describes the color capability of a terminal.
+type ColorProfile int
+
+const (
+	// ProfileAuto detects the profile from the environment (NO_COLOR, COLORTERM and TERM)
+	ProfileAuto ColorProfile = iota
+	// ProfileNoColor disables all escape sequences
+	ProfileNoColor
+	// ProfileANSI uses the 16 basic ANSI colors
+	ProfileANSI
+	// ProfileANSI256 uses the xterm 256 color palette
+	ProfileANSI256
+	// ProfileTrueColor uses 24 bit colors
+	ProfileTrueColor
+)
+
+// DetectColorProfile infers the ColorProfile of the current terminal.
+//
+// NO_COLOR (https://no-color.org) always wins; COLORTERM=truecolor|24bit selects
+// ProfileTrueColor and a TERM containing 256color selects ProfileANSI256.
+func DetectColorProfile() ColorProfile {
+	return detectColorProfile(os.Getenv)
+}
+
+func detectColorProfile(getenv func(string) string) ColorProfile {
+	if getenv("NO_COLOR") != "" {
+		return ProfileNoColor
+	}
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ProfileTrueColor
+	}
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case term == "" || term == "dumb":
+		return ProfileNoColor
+	case strings.Contains(term, "256color"):
+		return ProfileANSI256
+	default:
+		return ProfileANSI
+	}
+}
+
+const ansiReset = "\033[0m"
+
+// sequence returns the SGR foreground escape sequence of c for the profile
+func (p ColorProfile) sequence(c Color) string {
+	switch p {
+	case ProfileTrueColor:
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+	case ProfileANSI256:
+		return fmt.Sprintf("\033[38;5;%dm", ansi256(c))
+	case ProfileANSI:
+		return fmt.Sprintf("\033[%dm", ansi16(c))
+	default:
+		return ""
+	}
+}
+
+// ansi256 maps c onto the 6x6x6 color cube or the grayscale ramp of the xterm palette
+func ansi256(c Color) int {
+	if c.R == c.G && c.G == c.B {
+		switch {
+		case c.R < 8:
+			return 16
+		case c.R > 248:
+			return 231
+		default:
+			return 232 + int((float64(c.R)-8)/247*24+0.5)
+		}
+	}
+	q := func(v uint8) int { return int(float64(v)/255*5 + 0.5) }
+	return 16 + 36*q(c.R) + 6*q(c.G) + q(c.B)
+}
+
+var ansi16Palette = []Color{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// ansi16 returns the SGR code of the basic ANSI color nearest to c
+func ansi16(c Color) int {
+	best, bestDist := 0, -1
+	for i, p := range ansi16Palette {
+		dr, dg, db := int(c.R)-int(p.R), int(c.G)-int(p.G), int(c.B)-int(p.B)
+		if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	if best < 8 {
+		return 30 + best
+	}
+	return 90 + best - 8
+}
+
+// GradientDirection is the axis along which a gradient is interpolated.
+type GradientDirection int
+
+const (
+	// Horizontal interpolates the gradient from the left to the right column
+	Horizontal GradientDirection = iota
+	// Vertical interpolates the gradient from the top to the bottom row
+	Vertical
+)
+
+// painter colors the rows of rendered FIGlet art
+type painter struct {
+	profile   ColorProfile
+	from, to  Color
+	gradient  bool
+	direction GradientDirection
+}
+
+func (p *painter) colorAt(row, col, rows, cols int) Color {
+	if !p.gradient {
+		return p.from
+	}
+	pos, span := col, cols
+	if p.direction == Vertical {
+		pos, span = row, rows
+	}
+	if span <= 1 {
+		return p.from
+	}
+	return p.from.lerp(p.to, float64(pos)/float64(span-1))
+}
+
+// paint wraps every visible rune of lines in escape sequences. Sequences are only emitted
+// when the color changes, and every painted line is terminated by a reset.
+func (p *painter) paint(lines []string) []string {
+	if p == nil || p.profile == ProfileNoColor {
+		return lines
+	}
+	cols := 0
+	for _, l := range lines {
+		if n := displayWidth(l); n > cols {
+			cols = n
+		}
+	}
+
+	// columns are counted in cells, so that wide and combining characters do not
+	// shift the gradient
+	out := make([]string, len(lines))
+	for row, line := range lines {
+		sb := &strings.Builder{}
+		last := ""
+		col := 0
+		for _, r := range line {
+			if r != ' ' {
+				if seq := p.profile.sequence(p.colorAt(row, col, len(lines), cols)); seq != last {
+					sb.WriteString(seq)
+					last = seq
+				}
+			}
+			sb.WriteRune(r)
+			col += displayWidth(string(r))
+		}
+		if last != "" {
+			sb.WriteString(ansiReset)
+		}
+		out[row] = sb.String()
+	}
+	return out
+}
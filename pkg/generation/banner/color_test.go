@@ -0,0 +1,118 @@
+package banner_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+func TestParseColor(t *testing.T) {
+	tcs := []struct {
+		in       string
+		expected banner.Color
+		err      bool
+	}{
+		{"#ff8800", banner.Color{R: 255, G: 136}, false},
+		{"00FF00", banner.Color{G: 255}, false},
+		{"white", banner.Color{R: 255, G: 255, B: 255}, false},
+		{"#ff88", banner.Color{}, true},
+		{"not-a-color", banner.Color{}, true},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.in, func(t *testing.T) {
+			actual, err := banner.ParseColor(tt.in)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestGenerateBanner_Terminal(t *testing.T) {
+	red := banner.Color{R: 255}
+	blue := banner.Color{B: 255}
+
+	t.Run("uncolored", func(t *testing.T) {
+		actual := banner.GenerateBanner("ZSH", banner.KIND_TERMINAL)
+		shell := banner.GenerateBanner("ZSH", banner.KIND_SHELL)
+
+		assert.NotContains(t, actual, "#")
+		assert.NotContains(t, actual, "\033[")
+		for _, line := range strings.Split(strings.TrimSuffix(shell, "\n"), "\n") {
+			if strings.HasPrefix(line, "#  -") {
+				continue // the frame adapts to the prefix width
+			}
+			assert.Contains(t, actual, strings.TrimPrefix(line, "# "))
+		}
+	})
+
+	t.Run("solid truecolor", func(t *testing.T) {
+		actual := banner.GenerateBanner("ZSH", banner.KIND_TERMINAL,
+			banner.WithColor(red), banner.WithColorProfile(banner.ProfileTrueColor))
+		assert.Contains(t, actual, "\033[38;2;255;0;0m")
+		assert.Contains(t, actual, "\033[0m")
+	})
+
+	t.Run("solid 256", func(t *testing.T) {
+		actual := banner.GenerateBanner("ZSH", banner.KIND_TERMINAL,
+			banner.WithColor(red), banner.WithColorProfile(banner.ProfileANSI256))
+		assert.Contains(t, actual, "\033[38;5;196m")
+	})
+
+	t.Run("horizontal gradient", func(t *testing.T) {
+		actual := banner.GenerateBanner("ZSH", banner.KIND_TERMINAL,
+			banner.WithGradient(red, blue, banner.Horizontal), banner.WithColorProfile(banner.ProfileTrueColor))
+		assert.Contains(t, actual, "\033[38;2;255;0;0m")
+		assert.Contains(t, actual, "\033[38;2;0;0;255m")
+	})
+
+	t.Run("comment kinds stay uncolored", func(t *testing.T) {
+		actual := banner.GenerateBanner("ZSH", banner.KIND_SHELL,
+			banner.WithColor(red), banner.WithColorProfile(banner.ProfileTrueColor))
+		assert.Equal(t, banner.GenerateBanner("ZSH", banner.KIND_SHELL), actual)
+	})
+
+	t.Run("NO_COLOR", func(t *testing.T) {
+		prev, ok := os.LookupEnv("NO_COLOR")
+		os.Setenv("NO_COLOR", "1")
+		defer func() {
+			if ok {
+				os.Setenv("NO_COLOR", prev)
+			} else {
+				os.Unsetenv("NO_COLOR")
+			}
+		}()
+
+		actual := banner.GenerateBanner("ZSH", banner.KIND_TERMINAL, banner.WithColor(red))
+		assert.NotContains(t, actual, "\033[")
+	})
+}
+
+func TestGenerateBanner_GradientWideCharacters(t *testing.T) {
+	// a font of one row in which w is a full width character and all others are ..
+	sb := &strings.Builder{}
+	sb.WriteString("tlf2a$ 1 1 2 -1 0\n")
+	for c := ' '; c <= '~'; c++ {
+		if c == 'w' {
+			sb.WriteString("全@@\n")
+		} else {
+			sb.WriteString("..@@\n")
+		}
+	}
+	font, err := banner.ParseFont("wide", strings.NewReader(sb.String()))
+	require.NoError(t, err)
+
+	actual := banner.GenerateBanner("wa", banner.KIND_TERMINAL, banner.WithFontFace(font), banner.WithFrame(banner.FrameNone),
+		banner.WithGradient(banner.Color{R: 255}, banner.Color{B: 255}, banner.Horizontal), banner.WithColorProfile(banner.ProfileTrueColor))
+	// 全 occupies the cells 0 and 1, so the dots are painted at 2/3 and 3/3 of the gradient
+	assert.Equal(t, "\033[38;2;255;0;0m全\033[38;2;85;0;170m.\033[38;2;0;0;255m.\033[0m", strings.Split(actual, "\n")[0])
+}
@@ -0,0 +1,192 @@
+package banner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ControlFileSignature is the signature of FIGlet control files (.flc)
+const ControlFileSignature = "flc2a"
+
+// ControlFile is a parsed FIGlet control file. It maps input characters to the
+// characters looked up in the font before the text is rendered.
+//
+// Control files consist of stages separated by freeze (f) commands; the mappings of
+// each stage are applied to the output of the previous one. The input encoding
+// commands (h, j, b, u, g) are accepted but ignored as input is always UTF-8.
+type ControlFile struct {
+	Name   string
+	stages []map[rune]rune
+}
+
+// ParseControlFile parses a FIGlet control file.
+func ParseControlFile(name string, r io.Reader) (*ControlFile, error) {
+	s := bufio.NewScanner(r)
+	cf := &ControlFile{Name: name, stages: []map[rune]rune{{}}}
+
+	lineNo := 0
+	for s.Scan() {
+		lineNo++
+		line := strings.TrimRight(s.Text(), "\r")
+		if lineNo == 1 {
+			if !strings.HasPrefix(line, ControlFileSignature) {
+				return nil, fmt.Errorf("control file %s: unknown signature %q", name, line)
+			}
+			continue
+		}
+		if err := cf.parseCommand(line); err != nil {
+			return nil, fmt.Errorf("control file %s: line %d: %v", name, lineNo, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if lineNo == 0 {
+		return nil, fmt.Errorf("control file %s: empty control file", name)
+	}
+	return cf, nil
+}
+
+func (cf *ControlFile) parseCommand(line string) error {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed[0] == '#' {
+		return nil
+	}
+
+	stage := cf.stages[len(cf.stages)-1]
+	switch c := trimmed[0]; {
+	case c == 't':
+		args, err := splitControlArgs(strings.TrimLeft(trimmed[1:], " \t"))
+		if err != nil {
+			return err
+		}
+		if len(args) != 2 {
+			return fmt.Errorf("t command requires 2 arguments, got %d", len(args))
+		}
+		return addTranslation(stage, args[0], args[1])
+	case c == 'f':
+		cf.stages = append(cf.stages, map[rune]rune{})
+		return nil
+	case strings.ContainsRune("hjbug", rune(c)):
+		return nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		fields := strings.Fields(trimmed)
+		if len(fields) < 2 {
+			return fmt.Errorf("mapping requires 2 codes")
+		}
+		from, err := parseCode(fields[0])
+		if err != nil {
+			return err
+		}
+		to, err := parseCode(fields[1])
+		if err != nil {
+			return err
+		}
+		stage[from] = to
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", trimmed)
+	}
+}
+
+// addTranslation adds the mappings of a t command, where from and to are either
+// single characters or ranges (a-z)
+func addTranslation(stage map[rune]rune, from, to []rune) error {
+	expand := func(arg []rune) (lo, hi rune) {
+		if len(arg) == 3 && arg[1] == '-' {
+			return arg[0], arg[2]
+		}
+		return arg[0], arg[0]
+	}
+	fromLo, fromHi := expand(from)
+	toLo, toHi := expand(to)
+	if fromHi-fromLo != toHi-toLo {
+		return fmt.Errorf("ranges %q and %q differ in length", string(from), string(to))
+	}
+	for c := fromLo; c <= fromHi; c++ {
+		stage[c] = toLo + (c - fromLo)
+	}
+	return nil
+}
+
+// splitControlArgs splits the arguments of a t command into runes, resolving escapes.
+// Ranges are returned as the three runes lo, '-', hi.
+func splitControlArgs(s string) ([][]rune, error) {
+	var args [][]rune
+	for _, field := range strings.Fields(s) {
+		var arg []rune
+		for rest := field; rest != ""; {
+			c, n, err := unescape(rest)
+			if err != nil {
+				return nil, err
+			}
+			arg = append(arg, c)
+			rest = rest[n:]
+			// a literal '-' between two characters denotes a range
+			if len(arg) == 1 && strings.HasPrefix(rest, "-") && len(rest) > 1 {
+				arg = append(arg, '-')
+				rest = rest[1:]
+			}
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+var controlEscapes = map[byte]rune{
+	'a': '\a', 'b': '\b', 'e': 0x1b, 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v',
+	'\\': '\\', ' ': ' ', '-': '-',
+}
+
+// unescape reads one character of a control file argument and returns it with
+// the number of bytes consumed
+func unescape(s string) (rune, int, error) {
+	if s[0] != '\\' {
+		r := []rune(s)[0]
+		return r, len(string(r)), nil
+	}
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf("dangling escape")
+	}
+	negative := s[1] == '-' && len(s) > 2 && s[2] >= '0' && s[2] <= '9'
+	if c, ok := controlEscapes[s[1]]; ok && !negative {
+		return c, 2, nil
+	}
+	if negative || (s[1] >= '0' && s[1] <= '9') {
+		end := 2
+		for end < len(s) && strings.ContainsRune("0123456789abcdefABCDEFxX", rune(s[end])) {
+			end++
+		}
+		code, err := parseCode(s[1:end])
+		return code, end, err
+	}
+	return rune(s[1]), 2, nil
+}
+
+// parseCode parses a decimal, octal (0 prefix) or hexadecimal (0x prefix) character code
+func parseCode(s string) (rune, error) {
+	v, err := strconv.ParseInt(s, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid character code %q", s)
+	}
+	return rune(v), nil
+}
+
+// Translate applies the mappings of all stages to text
+func (cf *ControlFile) Translate(text string) string {
+	if cf == nil {
+		return text
+	}
+	out := []rune(text)
+	for _, stage := range cf.stages {
+		for i, c := range out {
+			if m, ok := stage[c]; ok {
+				out[i] = m
+			}
+		}
+	}
+	return string(out)
+}
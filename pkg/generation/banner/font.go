@@ -0,0 +1,235 @@
+package banner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FIGletSignature is the signature of FIGfont 2 files (.flf)
+	FIGletSignature = "flf2a"
+	// TOIletSignature is the signature of TOIlet font files (.tlf)
+	TOIletSignature = "tlf2a"
+)
+
+// deutschCodes are the required characters following ASCII 32-126 in every FIGfont
+var deutschCodes = []rune{196, 214, 220, 228, 246, 252, 223}
+
+// Font is a parsed FIGfont 2 or TOIlet font.
+type Font struct {
+	Name           string
+	Signature      string
+	Hardblank      rune
+	Height         int
+	Baseline       int
+	MaxLength      int
+	OldLayout      int
+	PrintDirection int
+	FullLayout     int
+	Comment        string
+
+	glyphs map[rune]glyph
+}
+
+// glyph is a FIGcharacter, one slice of runes per row
+type glyph [][]rune
+
+func (g glyph) width() int {
+	if len(g) == 0 {
+		return 0
+	}
+	return len(g[0])
+}
+
+// ParseFont parses a FIGfont 2 (flf2a) or TOIlet (tlf2a) font.
+func ParseFont(name string, r io.Reader) (*Font, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("font %s: empty font file", name)
+	}
+	f, commentLines, err := parseHeader(name, s.Text())
+	if err != nil {
+		return nil, err
+	}
+
+	comment := make([]string, 0, commentLines)
+	for i := 0; i < commentLines; i++ {
+		if !s.Scan() {
+			return nil, fmt.Errorf("font %s: unexpected end of file in comment section", name)
+		}
+		comment = append(comment, strings.TrimRight(s.Text(), "\r"))
+	}
+	f.Comment = strings.Join(comment, "\n")
+
+	line := commentLines + 1
+	required := make([]rune, 0, 95+len(deutschCodes))
+	for c := rune(32); c <= 126; c++ {
+		required = append(required, c)
+	}
+	required = append(required, deutschCodes...)
+
+	for _, code := range required {
+		g, n, err := f.readGlyph(s)
+		line += n
+		if err != nil {
+			// fonts are allowed to omit the deutsch characters
+			if err == io.EOF && code > 126 {
+				return f, nil
+			}
+			return nil, fmt.Errorf("font %s: line %d: character %d: %v", name, line, code, err)
+		}
+		f.glyphs[code] = g
+	}
+
+	// code tagged FIGcharacters follow the required ones until the end of the file
+	for s.Scan() {
+		line++
+		tag := strings.Fields(s.Text())
+		if len(tag) == 0 {
+			continue
+		}
+		code, err := strconv.ParseInt(tag[0], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("font %s: line %d: invalid code tag %q", name, line, tag[0])
+		}
+		g, n, err := f.readGlyph(s)
+		line += n
+		if err != nil {
+			return nil, fmt.Errorf("font %s: line %d: character %d: %v", name, line, code, err)
+		}
+		// negative codes cannot be typed and are reserved for translation tables
+		if code >= 0 {
+			f.glyphs[rune(code)] = g
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("font %s: %v", name, err)
+	}
+	return f, nil
+}
+
+func parseHeader(name, header string) (f *Font, commentLines int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 6 {
+		return nil, 0, fmt.Errorf("font %s: invalid header %q", name, header)
+	}
+
+	sig := fields[0]
+	if len(sig) < len(FIGletSignature)+1 || (!strings.HasPrefix(sig, FIGletSignature) && !strings.HasPrefix(sig, TOIletSignature)) {
+		return nil, 0, fmt.Errorf("font %s: unknown signature %q", name, sig)
+	}
+	hardblank := []rune(sig[len(FIGletSignature):])[0]
+
+	ints := make([]int, len(fields)-1)
+	for i, field := range fields[1:] {
+		if ints[i], err = strconv.Atoi(field); err != nil {
+			return nil, 0, fmt.Errorf("font %s: invalid header field %q", name, field)
+		}
+	}
+
+	f = &Font{
+		Name:      name,
+		Signature: sig[:len(FIGletSignature)],
+		Hardblank: hardblank,
+		Height:    ints[0],
+		Baseline:  ints[1],
+		MaxLength: ints[2],
+		OldLayout: ints[3],
+		glyphs:    map[rune]glyph{},
+	}
+	commentLines = ints[4]
+	if len(ints) > 5 {
+		f.PrintDirection = ints[5]
+	}
+
+	// derive the full layout from the old layout when the font does not specify one
+	switch {
+	case len(ints) > 6:
+		f.FullLayout = ints[6]
+	case f.OldLayout == 0:
+		f.FullLayout = smKern
+	case f.OldLayout < 0:
+		f.FullLayout = 0
+	default:
+		f.FullLayout = (f.OldLayout & 63) | smSmush
+	}
+
+	if f.Height < 1 {
+		return nil, 0, fmt.Errorf("font %s: invalid height %d", name, f.Height)
+	}
+	return f, commentLines, nil
+}
+
+// readGlyph reads Height lines of a FIGcharacter and strips the endmarks
+func (f *Font) readGlyph(s *bufio.Scanner) (g glyph, n int, err error) {
+	g = make(glyph, 0, f.Height)
+	width := 0
+	for row := 0; row < f.Height; row++ {
+		if !s.Scan() {
+			if err = s.Err(); err == nil {
+				err = io.EOF
+			}
+			return nil, n, err
+		}
+		n++
+		r := []rune(stripEndmarks(s.Text()))
+		if len(r) > width {
+			width = len(r)
+		}
+		g = append(g, r)
+	}
+	// pad ragged glyphs so that every row has the same width
+	for i, r := range g {
+		for len(r) < width {
+			r = append(r, ' ')
+		}
+		g[i] = r
+	}
+	return g, n, nil
+}
+
+func stripEndmarks(line string) string {
+	line = strings.TrimRight(line, " \t\r\n")
+	if line == "" {
+		return line
+	}
+	r := []rune(line)
+	end := r[len(r)-1]
+	k := len(r) - 1
+	for k >= 0 && r[k] == end {
+		k--
+	}
+	return string(r[:k+1])
+}
+
+// Has returns true if the font contains a FIGcharacter for r
+func (f *Font) Has(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+// renders returns true if the font contains a visible FIGcharacter for r. Fonts often
+// define the required deutsch characters as empty FIGcharacters.
+func (f *Font) renders(r rune) bool {
+	g, ok := f.glyphs[r]
+	return ok && g.width() > 0
+}
+
+// glyph returns the FIGcharacter of r, the missing character (code 0) or false
+func (f *Font) glyph(r rune) (glyph, bool) {
+	if g, ok := f.glyphs[r]; ok {
+		return g, true
+	}
+	if g, ok := f.glyphs[0]; ok {
+		return g, true
+	}
+	return nil, false
+}
@@ -0,0 +1,159 @@
+package banner_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alex-held/gold"
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+var layouts = []banner.Layout{
+	banner.LayoutFullWidth,
+	banner.LayoutFontDefault,
+	banner.LayoutFitting,
+	banner.LayoutSmushing,
+	banner.LayoutUniversalSmushing,
+}
+
+func loadFont(t *testing.T, name string) *banner.Font {
+	t.Helper()
+	font, err := banner.LoadFont(name)
+	require.NoError(t, err)
+	return font
+}
+
+func render(font *banner.Font, text string, layout banner.Layout, direction banner.Direction) string {
+	rows := font.Render(text, layout, direction)
+	for i, row := range rows {
+		rows[i] = strings.TrimRight(row, " ")
+	}
+	return strings.Join(rows, "\n") + "\n"
+}
+
+func loadTestFont(t *testing.T, name string) *banner.Font {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "fonts", name+".tlf"))
+	require.NoError(t, err)
+	defer f.Close()
+	font, err := banner.ParseFont(name, f)
+	require.NoError(t, err)
+	return font
+}
+
+// TestRender_Reference compares renderings with the output of the reference figlet
+// implementation, which testdata/reference/generate.sh writes for every font, layout and
+// direction.
+func TestRender_Reference(t *testing.T) {
+	fonts := map[string]*banner.Font{}
+	for _, name := range banner.Fonts() {
+		fonts[name] = loadFont(t, name)
+	}
+	tlfs, err := filepath.Glob(filepath.Join("testdata", "fonts", "*.tlf"))
+	require.NoError(t, err)
+	for _, path := range tlfs {
+		name := strings.TrimSuffix(filepath.Base(path), ".tlf")
+		fonts[name] = loadTestFont(t, name)
+	}
+	directions := map[string]banner.Direction{"": banner.DirectionFontDefault, "-rtl": banner.RightToLeft}
+
+	for name, font := range fonts {
+		for _, layout := range layouts {
+			for suffix, direction := range directions {
+				name, font, layout, direction := name, font, layout, direction
+				reference := name + "-" + layout.String() + suffix + "-Hello.txt"
+				t.Run(strings.TrimSuffix(reference, "-Hello.txt"), func(t *testing.T) {
+					expected, err := ioutil.ReadFile(filepath.Join("testdata", "reference", reference))
+					require.NoError(t, err, "run testdata/reference/generate.sh")
+					assert.Equal(t, string(expected), render(font, "Hello", layout, direction))
+				})
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, name := range banner.Fonts() {
+		font := loadFont(t, name)
+		for _, layout := range layouts {
+			t.Run(name+"/"+layout.String(), func(t *testing.T) {
+				g := gold.New(t, goldie.WithTestNameForDir(true), goldie.WithDiffEngine(goldie.ColoredDiff))
+				g.Assert(t, name+"-"+layout.String(), []byte(render(font, "devctl-kit", layout, banner.DirectionFontDefault)))
+			})
+		}
+	}
+}
+
+func TestRender_Vertical(t *testing.T) {
+	font := loadFont(t, "standard")
+	for _, layout := range layouts {
+		t.Run(layout.String(), func(t *testing.T) {
+			g := gold.New(t, goldie.WithTestNameForDir(true), goldie.WithDiffEngine(goldie.ColoredDiff))
+			g.Assert(t, layout.String(), []byte(render(font, "dev\nctl", layout, banner.DirectionFontDefault)))
+		})
+	}
+}
+
+func TestRender_RightToLeft(t *testing.T) {
+	font := loadFont(t, "standard")
+	ltr := render(font, "ab", banner.LayoutFullWidth, banner.LeftToRight)
+	rtl := render(font, "ba", banner.LayoutFullWidth, banner.RightToLeft)
+	assert.Equal(t, ltr, rtl)
+
+	g := gold.New(t, goldie.WithTestNameForDir(true), goldie.WithDiffEngine(goldie.ColoredDiff))
+	g.Assert(t, "smushing", []byte(render(font, "Hello", banner.LayoutSmushing, banner.RightToLeft)))
+}
+
+func TestParseFont_TOIlet(t *testing.T) {
+	font := loadTestFont(t, "mini")
+	assert.Equal(t, banner.TOIletSignature, font.Signature)
+	assert.Equal(t, 2, font.Height)
+	assert.Equal(t, "╭o╮╭k╮\n╰─╯╰─╯\n", render(font, "ok", banner.LayoutFontDefault, banner.DirectionFontDefault))
+}
+
+func TestParseFont_Invalid(t *testing.T) {
+	tcs := map[string]string{
+		"empty":     "",
+		"signature": "xyz2a$ 6 5 16 15 11 0 24463\n",
+		"header":    "flf2a$ six 5 16 15 11\n",
+		"truncated": "flf2a$ 1 1 2 -1 0\n@\n",
+	}
+	for name, content := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, err := banner.ParseFont(name, strings.NewReader(content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseControlFile(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "controls", "upper.flc"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	cf, err := banner.ParseControlFile("upper", f)
+	require.NoError(t, err)
+	assert.Equal(t, "BBC Z2", cf.Translate("abc Z1"))
+
+	withControl := banner.GenerateBanner("abc", banner.KIND_SHELL, banner.WithControlFile(cf))
+	assert.Equal(t, banner.GenerateBanner("BBC", banner.KIND_SHELL), withControl)
+}
+
+func TestParseControlFile_Escapes(t *testing.T) {
+	cf, err := banner.ParseControlFile("escapes", strings.NewReader("flc2a\nt \\- _\nt \\-1 x\nt \\t \\0x41\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "_1A", cf.Translate("-1\t"), `\-1 is the negative code -1, not the character -`)
+}
+
+func TestGenerateBanner_Layout(t *testing.T) {
+	full := banner.GenerateBanner("ZSH", banner.KIND_SHELL)
+	smushed := banner.GenerateBanner("ZSH", banner.KIND_SHELL, banner.WithLayout(banner.LayoutFontDefault))
+	assert.Less(t, len(smushed), len(full))
+}
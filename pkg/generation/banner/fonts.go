@@ -0,0 +1,42 @@
+package banner
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultFont is the font used when no font is selected
+const DefaultFont = "starwars"
+
+//go:embed fonts/*.flf
+var embeddedFonts embed.FS
+
+// Fonts returns the sorted names of the embedded fonts
+func Fonts() []string {
+	entries, _ := fs.ReadDir(embeddedFonts, "fonts")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadFont returns the embedded font name. Fonts are parsed once and shared, the
+// returned Font must not be modified.
+func LoadFont(name string) (*Font, error) {
+	return cachedFont(name, parseEmbeddedFont)
+}
+
+func parseEmbeddedFont(name string) (*Font, error) {
+	f, err := embeddedFonts.Open(path.Join("fonts", name+".flf"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown font %q", name)
+	}
+	defer f.Close()
+	return ParseFont(name, f)
+}
@@ -0,0 +1,181 @@
+package banner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrameStyle selects the decoration lines above and below the banner
+type FrameStyle int
+
+const (
+	// FrameDashes frames the banner with " - - -" lines
+	FrameDashes FrameStyle = iota
+	// FrameSolid frames the banner with " -----" lines
+	FrameSolid
+	// FrameDouble frames the banner with " =====" lines
+	FrameDouble
+	// FrameNone renders the banner without decoration lines
+	FrameNone
+)
+
+var frameNames = []string{"dashes", "solid", "double", "none"}
+
+func (s FrameStyle) String() string {
+	if int(s) < len(frameNames) {
+		return frameNames[s]
+	}
+	return fmt.Sprintf("FrameStyle(%d)", int(s))
+}
+
+// FrameStyles returns the names of all FrameStyles
+func FrameStyles() []string {
+	return append([]string{}, frameNames...)
+}
+
+// ParseFrameStyle returns the FrameStyle named name
+func ParseFrameStyle(name string) (FrameStyle, error) {
+	for i, n := range frameNames {
+		if strings.EqualFold(n, name) {
+			return FrameStyle(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown frame style %q", name)
+}
+
+// line returns a decoration line n columns wide
+func (s FrameStyle) line(n int) string {
+	if n < 2 {
+		n = 2
+	}
+	switch s {
+	case FrameSolid:
+		return " " + strings.Repeat("-", n-1)
+	case FrameDouble:
+		return " " + strings.Repeat("=", n-1)
+	default:
+		return strings.Repeat(" -", n/2)
+	}
+}
+
+// wrap breaks text into lines whose renderings are at most opts.Width columns wide.
+// Words wider than opts.Width are kept on a line of their own.
+func wrap(font *Font, text string, opts *Options) string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		current := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if current != "" {
+				candidate = current + " " + word
+			}
+			if current == "" || renderWidth(font, candidate, opts) <= opts.Width {
+				current = candidate
+				continue
+			}
+			lines = append(lines, current)
+			current = word
+		}
+		lines = append(lines, current)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderWidth returns the width of the widest row of text rendered with font
+func renderWidth(font *Font, text string, opts *Options) (width int) {
+	for _, row := range font.Render(text, opts.Layout, opts.Direction) {
+		if w := displayWidth(strings.TrimRight(row, " ")); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+// isFrameLine reports whether line is a decoration line of kind
+func isFrameLine(line string, kind OutputKind) bool {
+	text, ok := uncomment(line, kind)
+	if !ok {
+		return false
+	}
+	text = strings.ReplaceAll(text, " ", "")
+	return len(text) >= 3 && (strings.Trim(text, "-") == "" || strings.Trim(text, "=") == "")
+}
+
+// uncomment returns the text of a comment line of kind. Whitespace trimmed by editors
+// around empty comments is tolerated.
+func uncomment(line string, kind OutputKind) (string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	prefix, suffix := CommentPrefix(kind), CommentSuffix(kind)
+	switch {
+	case strings.HasPrefix(line, prefix):
+		line = strings.TrimPrefix(line, prefix)
+	case strings.HasPrefix(line, strings.TrimRight(prefix, " ")):
+		line = strings.TrimPrefix(line, strings.TrimRight(prefix, " "))
+	default:
+		return "", false
+	}
+	if suffix == "" {
+		return line, true
+	}
+	switch {
+	case strings.HasSuffix(line, suffix):
+		return strings.TrimSuffix(line, suffix), true
+	case strings.HasSuffix(line, strings.TrimLeft(suffix, " ")):
+		return strings.TrimSuffix(line, strings.TrimLeft(suffix, " ")), true
+	default:
+		return "", false
+	}
+}
+
+// find returns the line indices of the framing lines of the first banner of kind
+// starting at or after line from, or -1 if there is none
+func find(lines []string, kind OutputKind, from int) (begin, end int) {
+	for begin = from; begin < len(lines); begin++ {
+		if !isFrameLine(lines[begin], kind) {
+			continue
+		}
+		for i := begin + 1; i < len(lines); i++ {
+			if isFrameLine(lines[i], kind) {
+				return begin, i
+			}
+			if _, ok := uncomment(lines[i], kind); !ok {
+				break
+			}
+		}
+	}
+	return -1, -1
+}
+
+// locate returns the line indices of the framing lines of the banner starting the file,
+// optionally after a shebang, or -1 if there is none
+func locate(lines []string, kind OutputKind) (begin, end int) {
+	start := 0
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "#!") {
+		start = 1
+	}
+	if begin, end = find(lines, kind, start); begin != start {
+		return -1, -1
+	}
+	return begin, end
+}
+
+// Replace replaces the framed banner at the top of content with banner or, if content
+// does not start with one, inserts banner followed by a blank line. A shebang stays the
+// first line.
+func Replace(content, banner string, kind OutputKind) string {
+	lines := strings.SplitAfter(content, "\n")
+	begin, end := locate(lines, kind)
+	if begin >= 0 {
+		return strings.Join(lines[:begin], "") + banner + strings.Join(lines[end+1:], "")
+	}
+
+	head := ""
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "#!") {
+		head, lines = lines[0], lines[1:]
+	}
+	rest := strings.Join(lines, "")
+	if rest == "" {
+		return head + banner
+	}
+	return head + banner + "\n" + rest
+}
@@ -0,0 +1,260 @@
+package banner
+
+import "strings"
+
+// FIGfont 2 full_layout bits
+const (
+	smEqual      = 1
+	smLowline    = 2
+	smHierarchy  = 4
+	smPair       = 8
+	smBigX       = 16
+	smHardblank  = 32
+	smKern       = 64
+	smSmush      = 128
+	smVEqual     = 256
+	smVLowline   = 512
+	smVHierarchy = 1024
+	smVHLine     = 2048
+	smVVLine     = 4096
+	smVKern      = 8192
+	smVSmush     = 16384
+
+	smHRules = smEqual | smLowline | smHierarchy | smPair | smBigX | smHardblank
+	smVRules = smVEqual | smVLowline | smVHierarchy | smVHLine | smVVLine
+)
+
+// Layout selects how FIGcharacters are composed horizontally and how lines of
+// FIGcharacters are stacked vertically.
+type Layout int
+
+const (
+	// LayoutFullWidth places FIGcharacters side by side, each occupying its full width
+	LayoutFullWidth Layout = iota
+	// LayoutFontDefault uses the layout specified in the header of the font
+	LayoutFontDefault
+	// LayoutFitting moves FIGcharacters together until they touch (kerning)
+	LayoutFitting
+	// LayoutSmushing moves FIGcharacters one step further than fitting, merging the
+	// touching sub-characters using the smushing rules of the font. Fonts without
+	// smushing rules are smushed universally.
+	LayoutSmushing
+	// LayoutUniversalSmushing overlaps FIGcharacters ignoring the smushing rules of
+	// the font, letting the later sub-character win
+	LayoutUniversalSmushing
+)
+
+var layoutNames = map[Layout]string{
+	LayoutFullWidth:         "full",
+	LayoutFontDefault:       "default",
+	LayoutFitting:           "fitting",
+	LayoutSmushing:          "smushing",
+	LayoutUniversalSmushing: "universal",
+}
+
+func (l Layout) String() string {
+	return layoutNames[l]
+}
+
+// ParseLayout parses the name of a Layout (full, default, fitting, kerning, smushing, universal)
+func ParseLayout(s string) (Layout, bool) {
+	s = strings.ToLower(s)
+	if s == "kerning" {
+		return LayoutFitting, true
+	}
+	for l, name := range layoutNames {
+		if name == s {
+			return l, true
+		}
+	}
+	return LayoutFullWidth, false
+}
+
+// Direction is the print direction of the rendered text
+type Direction int
+
+const (
+	// DirectionFontDefault uses the print direction of the font header
+	DirectionFontDefault Direction = iota
+	// LeftToRight prints the text from left to right
+	LeftToRight
+	// RightToLeft prints the text from right to left
+	RightToLeft
+)
+
+// horizontalMode returns the horizontal layout bits for layout l in font f
+func (f *Font) horizontalMode(l Layout) int {
+	switch l {
+	case LayoutFontDefault:
+		mode := f.FullLayout & (smHRules | smKern | smSmush)
+		if mode&smSmush != 0 {
+			mode &^= smKern
+		}
+		return mode
+	case LayoutFitting:
+		return smKern
+	case LayoutSmushing:
+		return smSmush | (f.FullLayout & smHRules)
+	case LayoutUniversalSmushing:
+		return smSmush
+	default:
+		return 0
+	}
+}
+
+// verticalMode returns the vertical layout bits for layout l in font f
+func (f *Font) verticalMode(l Layout) int {
+	switch l {
+	case LayoutFontDefault:
+		mode := f.FullLayout & (smVRules | smVKern | smVSmush)
+		if mode&smVSmush != 0 {
+			mode &^= smVKern
+		}
+		return mode
+	case LayoutFitting:
+		return smVKern
+	case LayoutSmushing:
+		return smVSmush | (f.FullLayout & smVRules)
+	case LayoutUniversalSmushing:
+		return smVSmush
+	default:
+		return 0
+	}
+}
+
+// smushem merges the left sub-character lch with the right sub-character rch.
+// It returns false if the sub-characters cannot be smushed in mode.
+func (r *renderer) smushem(lch, rch rune) (rune, bool) {
+	if lch == ' ' {
+		return rch, true
+	}
+	if rch == ' ' {
+		return lch, true
+	}
+	if r.prevWidth < 2 || r.currWidth < 2 {
+		return 0, false
+	}
+	if r.hmode&smSmush == 0 {
+		return 0, false
+	}
+
+	hardblank := r.font.Hardblank
+	if r.hmode&smHRules == 0 {
+		// universal smushing
+		switch {
+		case lch == hardblank:
+			return rch, true
+		case rch == hardblank:
+			return lch, true
+		case r.rightToLeft:
+			return lch, true
+		default:
+			return rch, true
+		}
+	}
+
+	if r.hmode&smHardblank != 0 && lch == hardblank && rch == hardblank {
+		return lch, true
+	}
+	if lch == hardblank || rch == hardblank {
+		return 0, false
+	}
+	if r.hmode&smEqual != 0 && lch == rch {
+		return lch, true
+	}
+	if r.hmode&smLowline != 0 {
+		if lch == '_' && strings.ContainsRune("|/\\[]{}()<>", rch) {
+			return rch, true
+		}
+		if rch == '_' && strings.ContainsRune("|/\\[]{}()<>", lch) {
+			return lch, true
+		}
+	}
+	if r.hmode&smHierarchy != 0 {
+		if c, ok := hierarchy(lch, rch); ok {
+			return c, true
+		}
+	}
+	if r.hmode&smPair != 0 {
+		switch string([]rune{lch, rch}) {
+		case "[]", "][", "{}", "}{", "()", ")(":
+			return '|', true
+		}
+	}
+	if r.hmode&smBigX != 0 {
+		switch {
+		case lch == '/' && rch == '\\':
+			return '|', true
+		case lch == '\\' && rch == '/':
+			return 'Y', true
+		case lch == '>' && rch == '<':
+			return 'X', true
+		}
+	}
+	return 0, false
+}
+
+var hierarchyClasses = []string{"|", "/\\", "[]", "{}", "()", "<>"}
+
+// hierarchy implements the hierarchy smushing rule: the sub-character of the
+// higher class replaces the lower one.
+func hierarchy(a, b rune) (rune, bool) {
+	ca, cb := -1, -1
+	for i, class := range hierarchyClasses {
+		if strings.ContainsRune(class, a) {
+			ca = i
+		}
+		if strings.ContainsRune(class, b) {
+			cb = i
+		}
+	}
+	switch {
+	case ca < 0 || cb < 0 || ca == cb:
+		return 0, false
+	case ca < cb:
+		return b, true
+	default:
+		return a, true
+	}
+}
+
+// vsmushem merges the upper sub-character top with the lower sub-character bottom.
+func vsmushem(top, bottom rune, mode int) (rune, bool) {
+	if top == ' ' {
+		return bottom, true
+	}
+	if bottom == ' ' {
+		return top, true
+	}
+	if mode&smVSmush == 0 {
+		return 0, false
+	}
+	if mode&smVRules == 0 {
+		return bottom, true
+	}
+	if mode&smVEqual != 0 && top == bottom {
+		return top, true
+	}
+	if mode&smVLowline != 0 {
+		if top == '_' && strings.ContainsRune("|/\\[]{}()<>", bottom) {
+			return bottom, true
+		}
+		if bottom == '_' && strings.ContainsRune("|/\\[]{}()<>", top) {
+			return top, true
+		}
+	}
+	if mode&smVHierarchy != 0 {
+		if c, ok := hierarchy(top, bottom); ok {
+			return c, true
+		}
+	}
+	if mode&smVHLine != 0 {
+		if (top == '-' && bottom == '_') || (top == '_' && bottom == '-') {
+			return '=', true
+		}
+	}
+	if mode&smVVLine != 0 && top == '|' && bottom == '|' {
+		return '|', true
+	}
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package banner
+
+// Options configures the rendering of a single banner
+type Options struct {
+	Font      string
+	FontFace  *Font
+	Layout    Layout
+	Direction Direction
+	Control   *ControlFile
+	Color     *Color
+	Gradient  *Gradient
+	Profile   ColorProfile
+	Frame     FrameStyle
+	Width     int
+	Subtitle  string
+	Metadata  []Field
+}
+
+// Field is a metadata field rendered as "key: value" below the banner
+type Field struct {
+	Key, Value string
+}
+
+// Gradient describes a two color gradient along a GradientDirection
+type Gradient struct {
+	From, To  Color
+	Direction GradientDirection
+}
+
+type Option func(*Options) *Options
+
+// WithFont renders the banner using the embedded font name (see Fonts)
+func WithFont(name string) Option {
+	return func(o *Options) *Options {
+		o.Font = name
+		return o
+	}
+}
+
+// WithFontFace renders the banner using a font parsed by ParseFont
+func WithFontFace(f *Font) Option {
+	return func(o *Options) *Options {
+		o.FontFace = f
+		return o
+	}
+}
+
+// WithLayout selects the horizontal and vertical Layout. Banners default to
+// LayoutFullWidth.
+func WithLayout(l Layout) Option {
+	return func(o *Options) *Options {
+		o.Layout = l
+		return o
+	}
+}
+
+// WithDirection overrides the print direction of the font
+func WithDirection(d Direction) Option {
+	return func(o *Options) *Options {
+		o.Direction = d
+		return o
+	}
+}
+
+// WithControlFile translates the text using the control file before rendering
+func WithControlFile(cf *ControlFile) Option {
+	return func(o *Options) *Options {
+		o.Control = cf
+		return o
+	}
+}
+
+// WithColor paints KIND_TERMINAL banners in a solid color
+func WithColor(c Color) Option {
+	return func(o *Options) *Options {
+		o.Color = &c
+		return o
+	}
+}
+
+// WithGradient paints KIND_TERMINAL banners with a gradient from -> to along direction
+func WithGradient(from, to Color, direction GradientDirection) Option {
+	return func(o *Options) *Options {
+		o.Gradient = &Gradient{From: from, To: to, Direction: direction}
+		return o
+	}
+}
+
+// WithColorProfile overrides the detected ColorProfile of the terminal
+func WithColorProfile(p ColorProfile) Option {
+	return func(o *Options) *Options {
+		o.Profile = p
+		return o
+	}
+}
+
+// WithFrame selects the decoration lines framing the banner
+func WithFrame(style FrameStyle) Option {
+	return func(o *Options) *Options {
+		o.Frame = style
+		return o
+	}
+}
+
+// WithWidth wraps the text at word boundaries so that the rendered banner is at most
+// width columns wide, not counting the comment syntax
+func WithWidth(width int) Option {
+	return func(o *Options) *Options {
+		o.Width = width
+		return o
+	}
+}
+
+// WithSubtitle renders subtitle as plain text below the banner
+func WithSubtitle(subtitle string) Option {
+	return func(o *Options) *Options {
+		o.Subtitle = subtitle
+		return o
+	}
+}
+
+// WithMetadata adds a "key: value" line below the banner, e.g. the generator or the
+// source of a generated file. Fields keep the order in which they are added.
+func WithMetadata(key, value string) Option {
+	return func(o *Options) *Options {
+		o.Metadata = append(o.Metadata, Field{Key: key, Value: value})
+		return o
+	}
+}
+
+func newOptions(opts ...Option) *Options {
+	o := &Options{
+		Font:    DefaultFont,
+		Layout:  LayoutFullWidth,
+		Profile: ProfileAuto,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *Options) font() (*Font, error) {
+	if o.FontFace != nil {
+		return o.FontFace, nil
+	}
+	return LoadFont(o.Font)
+}
+
+// painter returns the painter for the configured colors or nil when the banner
+// stays uncolored
+func (o *Options) painter(kind OutputKind) *painter {
+	if kind != KIND_TERMINAL || (o.Color == nil && o.Gradient == nil) {
+		return nil
+	}
+	profile := o.Profile
+	if profile == ProfileAuto {
+		profile = DetectColorProfile()
+	}
+	if profile == ProfileNoColor {
+		return nil
+	}
+	if o.Gradient != nil {
+		return &painter{
+			profile:   profile,
+			from:      o.Gradient.From,
+			to:        o.Gradient.To,
+			gradient:  true,
+			direction: o.Gradient.Direction,
+		}
+	}
+	return &painter{profile: profile, from: *o.Color}
+}
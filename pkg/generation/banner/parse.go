@@ -0,0 +1,264 @@
+package banner
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrNoBanner is returned by Parse if the content does not contain a framed banner
+var ErrNoBanner = errors.New("no banner found")
+
+// parseKinds are the comment syntaxes tried by Parse, KIND_YAML banners are reported
+// as KIND_SHELL
+var parseKinds = []OutputKind{KIND_SHELL, KIND_GO, KIND_SQL, KIND_LISP, KIND_CSS, KIND_HTML}
+
+var fieldRe = regexp.MustCompile(`^([A-Za-z][\w.-]*): (.*)$`)
+
+// Parsed is a banner recovered from the content of a file
+type Parsed struct {
+	Kind OutputKind
+	// Title is the text of the FIGure and Font the embedded font it was rendered with.
+	// Both are empty if the FIGure could not be matched against an embedded font.
+	Title string
+	Font  string
+	// Art contains the rows of the FIGure without comment syntax
+	Art      []string
+	Subtitle string
+	Metadata []Field
+	// Begin and End are the line indices of the decoration lines
+	Begin, End int
+}
+
+// Get returns the value of the metadata field key
+func (p *Parsed) Get(key string) (string, bool) {
+	for _, f := range p.Metadata {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// Parse detects the first banner framed by decoration lines in content, e.g. a file
+// written by GenerateBanner, and recovers its title, subtitle and metadata.
+//
+// The comment syntaxes of kinds are tried in order, by default all of them. Titles are
+// recovered for banners using the full width layout of an embedded font. Fonts without
+// lowercase letters, e.g. starwars, decode to upper case.
+func Parse(content string, kinds ...OutputKind) (*Parsed, error) {
+	all, err := parse(content, true, kinds)
+	if err != nil {
+		return nil, err
+	}
+	return all[0], nil
+}
+
+// ParseAll returns all banners in content, e.g. the section banners of a shell script
+func ParseAll(content string, kinds ...OutputKind) ([]*Parsed, error) {
+	return parse(content, false, kinds)
+}
+
+func parse(content string, first bool, kinds []OutputKind) (out []*Parsed, err error) {
+	if len(kinds) == 0 {
+		kinds = parseKinds
+	}
+	lines := strings.SplitAfter(content, "\n")
+	for _, kind := range kinds {
+		for from := 0; from < len(lines); {
+			begin, end := find(lines, kind, from)
+			if begin < 0 {
+				break
+			}
+			out = append(out, parseBlock(lines, kind, begin, end))
+			if first {
+				return out, nil
+			}
+			from = end + 1
+		}
+		if len(out) > 0 {
+			return out, nil
+		}
+	}
+	return nil, ErrNoBanner
+}
+
+func parseBlock(lines []string, kind OutputKind, begin, end int) *Parsed {
+	p := &Parsed{Kind: kind, Begin: begin, End: end}
+	block := make([]string, 0, end-begin-1)
+	for _, line := range lines[begin+1 : end] {
+		text, _ := uncomment(line, kind)
+		block = append(block, strings.TrimRight(text, " "))
+	}
+	block = trimBlank(block)
+
+	// metadata is the trailing run of "key: value" lines
+	i := len(block)
+	for i > 0 && fieldRe.MatchString(block[i-1]) {
+		i--
+	}
+	for _, line := range block[i:] {
+		m := fieldRe.FindStringSubmatch(line)
+		p.Metadata = append(p.Metadata, Field{Key: m[1], Value: m[2]})
+	}
+	block = trimBlank(block[:i])
+
+	// the FIGure is the longest prefix matching an embedded font
+	p.Art = block
+	for n := len(block); n > 0; n-- {
+		if font, title, ok := matchTitle(block[:n]); ok {
+			p.Art, p.Font, p.Title = block[:n], font, title
+			var subtitle []string
+			for _, line := range block[n:] {
+				if line = strings.TrimSpace(line); line != "" {
+					subtitle = append(subtitle, line)
+				}
+			}
+			p.Subtitle = strings.Join(subtitle, " ")
+			break
+		}
+	}
+	return p
+}
+
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchTitle decodes art using the embedded fonts, starting with DefaultFont
+func matchTitle(art []string) (font, title string, ok bool) {
+	names := append([]string{DefaultFont}, Fonts()...)
+	for i, name := range names {
+		if i > 0 && name == DefaultFont {
+			continue
+		}
+		f, err := LoadFont(name)
+		if err != nil {
+			continue
+		}
+		if title, ok := f.decode(art); ok {
+			return name, title, true
+		}
+	}
+	return "", "", false
+}
+
+// decode recovers the text of a FIGure rendered with the full width layout. Wrapped
+// FIGures are decoded line by line and joined by spaces. The decoded text is verified
+// by rendering it again.
+func (f *Font) decode(art []string) (string, bool) {
+	lines, ok := f.decodeLines(art)
+	if !ok {
+		return "", false
+	}
+	text := strings.Join(lines, "\n")
+	rendered := trimBlank(strings.Split(figure(f, text, &Options{Layout: LayoutFullWidth}), "\n"))
+	if strings.Join(rendered, "\n") != strings.Join(art, "\n") {
+		return "", false
+	}
+	return strings.Join(lines, " "), true
+}
+
+// decodeLines splits art into blocks of at most Height rows and decodes each of them
+func (f *Font) decodeLines(art []string) ([]string, bool) {
+	if len(art) == 0 {
+		return nil, true
+	}
+	for h := f.Height; h > 0; h-- {
+		if h > len(art) {
+			continue
+		}
+		line, ok := f.decodeLine(art[:h])
+		if !ok || strings.TrimSpace(line) == "" {
+			continue
+		}
+		if rest, ok := f.decodeLines(trimBlank(art[h:])); ok {
+			return append([]string{line}, rest...), true
+		}
+	}
+	return nil, false
+}
+
+// decodeLine decodes the rows of a single line of text rendered without overlap
+func (f *Font) decodeLine(rows []string) (string, bool) {
+	grid := make([][]rune, f.Height)
+	width := 0
+	for i := range grid {
+		if i < len(rows) {
+			grid[i] = []rune(rows[i])
+		}
+		if len(grid[i]) > width {
+			width = len(grid[i])
+		}
+	}
+
+	candidates := f.candidates()
+	// failed[col] memoizes columns from which the rest of the rows cannot be decoded
+	failed := map[int]bool{}
+	var solve func(col int) (string, bool)
+	solve = func(col int) (string, bool) {
+		if col >= width {
+			return "", true
+		}
+		if failed[col] {
+			return "", false
+		}
+		for _, r := range candidates {
+			g := f.glyphs[r]
+			if g.width() == 0 || !f.matches(grid, g, col) {
+				continue
+			}
+			if rest, ok := solve(col + g.width()); ok {
+				return string(r) + rest, true
+			}
+		}
+		failed[col] = true
+		return "", false
+	}
+	return solve(0)
+}
+
+// matches reports whether g is rendered at column col of grid. Cells beyond the end of
+// a row are blank.
+func (f *Font) matches(grid [][]rune, g glyph, col int) bool {
+	for row, runes := range g {
+		for i, want := range runes {
+			if want == f.Hardblank {
+				want = ' '
+			}
+			got := ' '
+			if col+i < len(grid[row]) {
+				got = grid[row][col+i]
+			}
+			if got != want {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// candidates returns the printable characters of the font, widest first so that
+// blank columns are not consumed by narrow glyphs
+func (f *Font) candidates() []rune {
+	order := make([]rune, 0, len(f.glyphs))
+	for r := range f.glyphs {
+		if r >= ' ' {
+			order = append(order, r)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if wi, wj := f.glyphs[order[i]].width(), f.glyphs[order[j]].width(); wi != wj {
+			return wi > wj
+		}
+		return order[i] < order[j]
+	})
+	return order
+}
@@ -0,0 +1,219 @@
+package banner
+
+import (
+	"strings"
+)
+
+// renderer composes FIGcharacters into rows of sub-characters
+type renderer struct {
+	font        *Font
+	hmode       int
+	vmode       int
+	rightToLeft bool
+
+	prevWidth int
+	currWidth int
+}
+
+func newRenderer(f *Font, layout Layout, direction Direction) *renderer {
+	r := &renderer{
+		font:        f,
+		hmode:       f.horizontalMode(layout),
+		vmode:       f.verticalMode(layout),
+		rightToLeft: f.PrintDirection == 1,
+	}
+	switch direction {
+	case LeftToRight:
+		r.rightToLeft = false
+	case RightToLeft:
+		r.rightToLeft = true
+	}
+	return r
+}
+
+// Render renders text using the font and returns the rows of the FIGure.
+// Hardblanks are replaced by spaces and trailing whitespace is preserved.
+func (f *Font) Render(text string, layout Layout, direction Direction) []string {
+	return newRenderer(f, layout, direction).render(text)
+}
+
+func (r *renderer) render(text string) []string {
+	var block [][]rune
+	for i, line := range strings.Split(text, "\n") {
+		rows := r.renderLine(line)
+		if i == 0 {
+			block = rows
+			continue
+		}
+		block = r.stack(block, rows)
+	}
+
+	out := make([]string, len(block))
+	for i, row := range block {
+		out[i] = strings.ReplaceAll(string(row), string(r.font.Hardblank), " ")
+	}
+	return out
+}
+
+// renderLine composes the FIGcharacters of a single line of text
+func (r *renderer) renderLine(line string) [][]rune {
+	rows := make([][]rune, r.font.Height)
+	r.prevWidth = 0
+	for _, c := range r.font.transliterate(line) {
+		g, ok := r.font.glyph(c)
+		if !ok {
+			continue
+		}
+		rows = r.addGlyph(rows, g)
+	}
+	return rows
+}
+
+// addGlyph appends g to rows, overlapping it by the amount the layout allows
+func (r *renderer) addGlyph(rows [][]rune, g glyph) [][]rune {
+	r.currWidth = g.width()
+	amount := r.smushAmount(rows, g)
+
+	for i := range rows {
+		curr := g[i]
+		if r.rightToLeft {
+			line := make([]rune, 0, len(curr)+len(rows[i]))
+			line = append(line, curr[:len(curr)-amount]...)
+			for k := 0; k < amount && k < len(rows[i]); k++ {
+				c, _ := r.smushem(curr[len(curr)-amount+k], rows[i][k])
+				line = append(line, c)
+			}
+			if amount < len(rows[i]) {
+				line = append(line, rows[i][amount:]...)
+			}
+			rows[i] = line
+			continue
+		}
+		// like figlet, sub-characters overlapping the start of an empty line are dropped
+		for k := 0; k < amount; k++ {
+			if pos := len(rows[i]) - amount + k; pos >= 0 {
+				rows[i][pos], _ = r.smushem(rows[i][pos], curr[k])
+			}
+		}
+		rows[i] = append(rows[i], curr[amount:]...)
+	}
+	r.prevWidth = r.currWidth
+	return rows
+}
+
+// smushAmount returns the number of columns g can overlap the existing rows
+func (r *renderer) smushAmount(rows [][]rune, g glyph) int {
+	if r.hmode&(smSmush|smKern) == 0 || len(rows) == 0 {
+		return 0
+	}
+	max := r.currWidth
+	for i := range rows {
+		var left, right []rune
+		if r.rightToLeft {
+			left, right = g[i], rows[i]
+		} else {
+			left, right = rows[i], g[i]
+		}
+
+		// boundary of the left sub-characters; like figlet it never moves past column 0
+		lb, lch := len(left), rune(0)
+		for {
+			lch = 0
+			if lb < len(left) {
+				lch = left[lb]
+			}
+			if lb == 0 || (lch != 0 && lch != ' ') {
+				break
+			}
+			lb--
+		}
+		// boundary of the right sub-characters
+		rb, rch := 0, rune(0)
+		for rb < len(right) && right[rb] == ' ' {
+			rb++
+		}
+		if rb < len(right) {
+			rch = right[rb]
+		}
+
+		amt := rb + len(left) - 1 - lb
+		if lch == 0 || lch == ' ' {
+			amt++
+		} else if rch != 0 {
+			if _, ok := r.smushem(lch, rch); ok {
+				amt++
+			}
+		}
+		if amt < max {
+			max = amt
+		}
+	}
+	if max > r.currWidth {
+		max = r.currWidth
+	}
+	if max < 0 {
+		max = 0
+	}
+	return max
+}
+
+// stack places lower below upper, overlapping rows as the vertical layout allows
+func (r *renderer) stack(upper, lower [][]rune) [][]rune {
+	width := 0
+	for _, row := range append(append([][]rune{}, upper...), lower...) {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	pad := func(rows [][]rune) [][]rune {
+		out := make([][]rune, len(rows))
+		for i, row := range rows {
+			out[i] = append(append([]rune{}, row...), []rune(strings.Repeat(" ", width-len(row)))...)
+		}
+		return out
+	}
+	upper, lower = pad(upper), pad(lower)
+
+	amount := r.vsmushAmount(upper, lower)
+	out := upper[:len(upper)-amount]
+	for i := 0; i < amount; i++ {
+		top, bottom := upper[len(upper)-amount+i], lower[i]
+		merged := make([]rune, width)
+		for c := range merged {
+			merged[c], _ = vsmushem(top[c], bottom[c], r.vmode)
+		}
+		out = append(out, merged)
+	}
+	return append(out, lower[amount:]...)
+}
+
+// vsmushAmount returns the number of rows lower can overlap upper
+func (r *renderer) vsmushAmount(upper, lower [][]rune) int {
+	if r.vmode&(smVSmush|smVKern) == 0 {
+		return 0
+	}
+	fits := func(amount int, mode int) bool {
+		for i := 0; i < amount; i++ {
+			top, bottom := upper[len(upper)-amount+i], lower[i]
+			for c := range top {
+				if _, ok := vsmushem(top[c], bottom[c], mode); !ok {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	max := len(upper)
+	if len(lower) < max {
+		max = len(lower)
+	}
+	fit := 0
+	for fit < max && fits(fit+1, smVKern) {
+		fit++
+	}
+	if r.vmode&smVSmush != 0 && fit < max && fits(fit+1, r.vmode) {
+		fit++
+	}
+	return fit
+}
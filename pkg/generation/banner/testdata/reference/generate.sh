@@ -0,0 +1,38 @@
+#!/bin/sh
+# Renders "Hello" with the reference figlet implementation in every layout and print
+# direction of the bundled fonts and the TOIlet fonts of testdata/fonts. figlet reads
+# TOIlet fonts since 2.2.4, toilet itself can not print right to left. The output is
+# left justified, as figlet justifies right to left text to the output width, and
+# trailing blanks are trimmed, as the tests compare the trimmed renderings.
+#
+# usage: testdata/reference/generate.sh  (from pkg/generation/banner)
+set -eu
+
+dir=testdata/reference
+text=Hello
+
+# layout name and figlet option, see banner.Layout
+layouts="full:-W default: fitting:-k smushing:-S universal:-o"
+
+render() {
+	fontdir=$1 font=$2
+	for layout in $layouts; do
+		name=${layout%%:*} opt=${layout#*:}
+		for direction in default rtl; do
+			case $direction in
+			default) suffix= dopt= ;;
+			rtl) suffix=-rtl dopt=-R ;;
+			esac
+			# shellcheck disable=SC2086
+			figlet -d "$fontdir" -f "$font" -w 10000 -l $opt $dopt "$text" |
+				sed 's/[[:space:]]*$//' >"$dir/$font-$name$suffix-$text.txt"
+		done
+	done
+}
+
+for f in fonts/*.flf; do
+	render fonts "$(basename "$f" .flf)"
+done
+for f in testdata/fonts/*.tlf; do
+	render testdata/fonts "$(basename "$f" .tlf)"
+done
@@ -0,0 +1,84 @@
+package banner
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// transliterations are ASCII replacements preferred over the base character of the
+// decomposition, e.g. for German umlauts, or for characters that do not decompose
+var transliterations = map[rune]string{
+	'Ä': "Ae", 'Ö': "Oe", 'Ü': "Ue", 'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss", 'ẞ': "SS",
+	'Æ': "AE", 'æ': "ae", 'Œ': "OE", 'œ': "oe", 'Ø': "O", 'ø': "o", 'Ł': "L", 'ł': "l",
+	'Đ': "D", 'đ': "d", 'Þ': "Th", 'þ': "th", 'ı': "i",
+	'‘': "'", '’': "'", '‚': "'", '“': "\"", '”': "\"", '„': "\"", '«': "<<", '»': ">>",
+	'‐': "-", '–': "-", '—': "-", '…': "...", '×': "x", '€': "EUR", '\u00a0': " ",
+}
+
+// transliterate replaces the characters of text the font cannot render by ASCII
+// replacements or their base characters, e.g. "Größe" => "Groesse" and "é" => "e".
+// Characters without replacement are kept and rendered as the missing character.
+func (f *Font) transliterate(text string) string {
+	sb := &strings.Builder{}
+	for _, r := range text {
+		if r < ' ' || f.renders(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		if t, ok := transliterations[r]; ok && f.rendersAll(t) {
+			sb.WriteString(t)
+			continue
+		}
+		if base := stripMarks(r); base != "" && f.rendersAll(base) {
+			sb.WriteString(base)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func (f *Font) rendersAll(s string) bool {
+	for _, r := range s {
+		if !f.renders(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripMarks returns the canonical decomposition of r without combining marks
+func stripMarks(r rune) string {
+	sb := &strings.Builder{}
+	for _, d := range norm.NFD.String(string(r)) {
+		if !unicode.Is(unicode.Mn, d) {
+			sb.WriteRune(d)
+		}
+	}
+	if sb.String() == string(r) {
+		return ""
+	}
+	return sb.String()
+}
+
+// displayWidth returns the number of terminal columns of s. East Asian wide and
+// fullwidth characters take two columns, combining marks and zero width characters none.
+func displayWidth(s string) (n int) {
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), unicode.Is(unicode.Cf, r):
+		case r < ' ':
+		default:
+			switch width.LookupRune(r).Kind() {
+			case width.EastAsianWide, width.EastAsianFullwidth:
+				n += 2
+			default:
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package banner_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+func parseTestFont(t *testing.T, name string) *banner.Font {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "fonts", name))
+	require.NoError(t, err)
+	defer f.Close()
+
+	font, err := banner.ParseFont(name, f)
+	require.NoError(t, err)
+	return font
+}
+
+func TestParseFont_CodeTagged(t *testing.T) {
+	font := parseTestFont(t, "tagged.tlf")
+	assert.True(t, font.Has('✓'))
+	assert.True(t, font.Has('ä'))
+	assert.False(t, font.Has(-2))
+	assert.Equal(t, "╭o╮╭k╮╭✓╮\n╰─╯╰─╯╰─╯\n", render(font, "ok✓", banner.LayoutFontDefault, banner.DirectionFontDefault))
+
+	standard := loadFont(t, "standard")
+	assert.True(t, standard.Has(0x0100), "LATIN CAPITAL LETTER A WITH MACRON is code tagged in standard.flf")
+}
+
+func TestParseFont_InvalidCodeTag(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "fonts", "tagged.tlf"))
+	require.NoError(t, err)
+
+	_, err = banner.ParseFont("mini", strings.NewReader(string(b)+"0xZZ  INVALID\n╭?╮@\n╰─╯@@\n"))
+	assert.Error(t, err)
+	_, err = banner.ParseFont("mini", strings.NewReader(string(b)+"0x2713  TRUNCATED\n╭✓╮@\n"))
+	assert.Error(t, err)
+}
+
+func TestRender_Transliteration(t *testing.T) {
+	font := parseTestFont(t, "tagged.tlf")
+	tcs := map[string]string{
+		"Größe":       "Groesse",
+		"café":        "cafe",
+		"ä":           "ä",  // code tagged
+		"ö":           "oe", // empty deutsch character
+		"Ørsted – Øl": "Orsted - Ol",
+	}
+	for text, expected := range tcs {
+		t.Run(text, func(t *testing.T) {
+			assert.Equal(t,
+				render(font, expected, banner.LayoutFontDefault, banner.DirectionFontDefault),
+				render(font, text, banner.LayoutFontDefault, banner.DirectionFontDefault))
+		})
+	}
+
+	// the deutsch characters of standard.flf are rendered natively
+	standard := loadFont(t, "standard")
+	assert.NotEqual(t,
+		render(standard, "Oe", banner.LayoutFullWidth, banner.DirectionFontDefault),
+		render(standard, "Ö", banner.LayoutFullWidth, banner.DirectionFontDefault))
+}
+
+func TestGenerateBanner_DisplayWidth(t *testing.T) {
+	frameWidth := func(out string) int {
+		return len(strings.TrimPrefix(strings.SplitN(out, "\n", 2)[0], "# "))
+	}
+	ascii := banner.GenerateBanner("x", banner.KIND_SHELL, banner.WithFont("small"), banner.WithFrame(banner.FrameSolid), banner.WithSubtitle(strings.Repeat("ab", 20)))
+	wide := banner.GenerateBanner("x", banner.KIND_SHELL, banner.WithFont("small"), banner.WithFrame(banner.FrameSolid), banner.WithSubtitle(strings.Repeat("日", 20)))
+	combining := banner.GenerateBanner("x", banner.KIND_SHELL, banner.WithFont("small"), banner.WithFrame(banner.FrameSolid), banner.WithSubtitle(strings.Repeat("é", 40)))
+
+	assert.Equal(t, frameWidth(ascii), frameWidth(wide))
+	assert.Equal(t, frameWidth(ascii), frameWidth(combining))
+}
@@ -0,0 +1,86 @@
+package generation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// words splits s into words at separators, case changes and letter/digit boundaries,
+// e.g. "devctlKit-HTTPServer_v2" => [devctl Kit HTTP Server v2]
+func words(s string) (out []string) {
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				out = append(out, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			// fooBar
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// HTTPServer
+		case unicode.IsDigit(r) != unicode.IsDigit(prev) && !unicode.IsDigit(r):
+			// v2ray
+		default:
+			continue
+		}
+		out = append(out, string(runes[start:i]))
+		start = i
+	}
+	if start >= 0 {
+		out = append(out, string(runes[start:]))
+	}
+	return out
+}
+
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// CamelCase converts s to camelCase
+func CamelCase(s string) string {
+	out := &strings.Builder{}
+	for i, w := range words(s) {
+		if i == 0 {
+			out.WriteString(strings.ToLower(w))
+			continue
+		}
+		out.WriteString(capitalize(w))
+	}
+	return out.String()
+}
+
+// PascalCase converts s to PascalCase
+func PascalCase(s string) string {
+	out := &strings.Builder{}
+	for _, w := range words(s) {
+		out.WriteString(capitalize(w))
+	}
+	return out.String()
+}
+
+// SnakeCase converts s to snake_case
+func SnakeCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+// ScreamingSnakeCase converts s to SCREAMING_SNAKE_CASE, e.g. for environment variables
+func ScreamingSnakeCase(s string) string {
+	return strings.ToUpper(strings.Join(words(s), "_"))
+}
+
+// KebabCase converts s to kebab-case
+func KebabCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
@@ -0,0 +1,179 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines surrounding a hunk
+const DefaultContext = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+	a, b int // line indices in a and b
+}
+
+// Unified returns the unified diff between a and b with DefaultContext lines of
+// context, or an empty string if they are equal.
+func Unified(fromName, toName, a, b string) string {
+	return UnifiedContext(fromName, toName, a, b, DefaultContext)
+}
+
+// UnifiedContext returns the unified diff between a and b with n lines of context.
+func UnifiedContext(fromName, toName, a, b string, n int) string {
+	if a == b {
+		return ""
+	}
+	al, bl := splitLines(a), splitLines(b)
+	ops := diffLines(al, bl)
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(ops, n) {
+		writeHunk(sb, h)
+	}
+	return sb.String()
+}
+
+// splitLines splits s into lines, keeping the line terminators so that a missing
+// newline at the end of the file shows up in the diff
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes the shortest edit script between a and b using the longest common subsequence
+func diffLines(a, b []string) []op {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i], i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j], i, j})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i], i, j})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j], i, j})
+	}
+	return ops
+}
+
+// hunks groups the changes of ops with n lines of surrounding context
+func hunks(ops []op, n int) (out [][]op) {
+	var current []op
+	lastChange := -1
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		start := i - n
+		if start < 0 {
+			start = 0
+		}
+		if current != nil && start <= lastChange+n+1 {
+			current = append(current, ops[lastChange+1:i+1]...)
+		} else {
+			if current != nil {
+				out = append(out, closeHunk(current, ops, lastChange, n))
+			}
+			current = append([]op{}, ops[start:i+1]...)
+		}
+		lastChange = i
+	}
+	if current != nil {
+		out = append(out, closeHunk(current, ops, lastChange, n))
+	}
+	return out
+}
+
+func closeHunk(h []op, ops []op, lastChange, n int) []op {
+	end := lastChange + 1 + n
+	if end > len(ops) {
+		end = len(ops)
+	}
+	return append(h, ops[lastChange+1:end]...)
+}
+
+func writeHunk(sb *strings.Builder, h []op) {
+	aStart, bStart := h[0].a, h[0].b
+	aCount, bCount := 0, 0
+	for _, o := range h {
+		switch o.kind {
+		case opEqual:
+			aCount++
+			bCount++
+		case opDelete:
+			aCount++
+		case opInsert:
+			bCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+	for _, o := range h {
+		prefix := " "
+		switch o.kind {
+		case opDelete:
+			prefix = "-"
+		case opInsert:
+			prefix = "+"
+		}
+		sb.WriteString(prefix)
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,48 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/diff"
+)
+
+func TestUnified(t *testing.T) {
+	tcs := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"change",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			"--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"insert into empty",
+			"",
+			"x\n",
+			"--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			"missing newline",
+			"a\n",
+			"a",
+			"--- a\n+++ b\n@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, diff.Unified("a", "b", tt.a, tt.b))
+		})
+	}
+}
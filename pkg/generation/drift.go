@@ -0,0 +1,160 @@
+package generation
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/generation/diff"
+)
+
+// DriftKind describes how a file on disk differs from its generated content
+type DriftKind string
+
+const (
+	// DriftAdded is a file in an owned output directory that is not generated
+	DriftAdded DriftKind = "added"
+	// DriftChanged is a generated file whose content was edited
+	DriftChanged DriftKind = "changed"
+	// DriftMissing is a generated file that does not exist on disk
+	DriftMissing DriftKind = "missing"
+)
+
+// Output is a generated template tree registered with a Checker
+type Output struct {
+	Name string
+	// Src, Root, Dst and Data are passed to Engine.Render
+	Src  fs.FS
+	Root string
+	Dst  string
+	Data interface{}
+	// Owned reports files below Dst that are not rendered from Src as DriftAdded
+	Owned bool
+}
+
+// Drift is a single file that differs from its generated content
+type Drift struct {
+	Output string
+	Path   string
+	Kind   DriftKind
+	// Diff is the unified diff from the generated to the on disk content
+	Diff string
+}
+
+// DriftReport lists the drifted files of all outputs, sorted by path
+type DriftReport struct {
+	Drifts []Drift
+}
+
+// String returns a summary line per drifted file followed by the diffs
+func (r *DriftReport) String() string {
+	sb := &strings.Builder{}
+	for _, d := range r.Drifts {
+		fmt.Fprintf(sb, "%-8s %s (%s)\n", d.Kind, d.Path, d.Output)
+	}
+	for _, d := range r.Drifts {
+		sb.WriteString("\n")
+		sb.WriteString(d.Diff)
+	}
+	return sb.String()
+}
+
+// Checker detects hand edits of generated files by re-rendering registered outputs
+// into memory and comparing them with the files on disk
+type Checker struct {
+	fs      afero.Fs
+	opts    []Option
+	outputs []Output
+}
+
+// NewChecker returns a Checker comparing against fs. The Options configure the
+// Engine used for rendering and must match those used for generation.
+func NewChecker(fs afero.Fs, opts ...Option) *Checker {
+	return &Checker{fs: fs, opts: opts}
+}
+
+// Register adds an Output to check
+func (c *Checker) Register(outputs ...Output) *Checker {
+	c.outputs = append(c.outputs, outputs...)
+	return c
+}
+
+// Check renders all registered outputs and compares them with the files on disk.
+// If any file drifted an *constants.ExitError with constants.IssuesFound is returned.
+func (c *Checker) Check() (*DriftReport, error) {
+	report := &DriftReport{}
+	for _, o := range c.outputs {
+		drifts, err := c.check(o)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check output %s", o.Name)
+		}
+		report.Drifts = append(report.Drifts, drifts...)
+	}
+	sort.SliceStable(report.Drifts, func(i, j int) bool { return report.Drifts[i].Path < report.Drifts[j].Path })
+
+	if len(report.Drifts) > 0 {
+		return report, &constants.ExitError{
+			ExitCode: constants.IssuesFound,
+			Message:  fmt.Sprintf("%d generated files drifted", len(report.Drifts)),
+		}
+	}
+	return report, nil
+}
+
+func (c *Checker) check(o Output) (drifts []Drift, err error) {
+	files, err := NewEngine(afero.NewMemMapFs(), c.opts...).Plan(o.Src, o.Root, o.Dst, o.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	generated := map[string]bool{}
+	for _, f := range files {
+		generated[f.Path] = true
+		current, err := afero.ReadFile(c.fs, f.Path)
+		switch {
+		case os.IsNotExist(err):
+			drifts = append(drifts, Drift{
+				Output: o.Name,
+				Path:   f.Path,
+				Kind:   DriftMissing,
+				Diff:   diff.Unified(f.Path, "/dev/null", string(f.Content), ""),
+			})
+		case err != nil:
+			return nil, errors.Wrapf(err, "failed to read %s", f.Path)
+		case string(current) != string(f.Content):
+			drifts = append(drifts, Drift{
+				Output: o.Name,
+				Path:   f.Path,
+				Kind:   DriftChanged,
+				Diff:   diff.Unified(f.Path+" (generated)", f.Path, string(f.Content), string(current)),
+			})
+		}
+	}
+
+	if exists, _ := afero.DirExists(c.fs, o.Dst); !o.Owned || !exists {
+		return drifts, nil
+	}
+	err = afero.Walk(c.fs, o.Dst, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || generated[path] {
+			return err
+		}
+		current, err := afero.ReadFile(c.fs, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		drifts = append(drifts, Drift{
+			Output: o.Name,
+			Path:   path,
+			Kind:   DriftAdded,
+			Diff:   diff.Unified("/dev/null", path, "", string(current)),
+		})
+		return nil
+	})
+	return drifts, err
+}
@@ -0,0 +1,86 @@
+package generation_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/generation"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+func newChecker(fs afero.Fs, owned bool) *generation.Checker {
+	return generation.NewChecker(fs,
+		generation.WithPaths(env.NewPaths("/devctl")),
+		generation.WithRuntimeInfo(system.RuntimeInfo{OS: "linux", Arch: "amd64"}),
+	).Register(generation.Output{
+		Name:  "tool",
+		Src:   templates,
+		Root:  "tmpl",
+		Dst:   "/out",
+		Data:  data{Name: "x", Command: "run"},
+		Owned: owned,
+	})
+}
+
+func TestChecker_NoDrift(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := newEngine(fs).Render(templates, "tmpl", "/out", data{Name: "x", Command: "run"})
+	require.NoError(t, err)
+
+	report, err := newChecker(fs, true).Check()
+	require.NoError(t, err)
+	assert.Empty(t, report.Drifts)
+}
+
+func TestChecker_Drift(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := newEngine(fs).Render(templates, "tmpl", "/out", data{Name: "x", Command: "run"})
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "/out/bin/run.sh", []byte("#!/bin/sh\nexec 'run' --verbose\n"), 0755))
+	require.NoError(t, fs.Remove("/out/static.txt"))
+	require.NoError(t, afero.WriteFile(fs, "/out/notes.txt", []byte("todo\n"), 0644))
+
+	report, err := newChecker(fs, true).Check()
+	require.Error(t, err)
+	exitErr, ok := err.(*constants.ExitError)
+	require.True(t, ok)
+	assert.Equal(t, constants.IssuesFound, int(exitErr.ExitCode))
+
+	require.Len(t, report.Drifts, 3)
+	assert.Equal(t, generation.Drift{
+		Output: "tool",
+		Path:   "/out/bin/run.sh",
+		Kind:   generation.DriftChanged,
+		Diff: `--- /out/bin/run.sh (generated)
++++ /out/bin/run.sh
+@@ -1,2 +1,2 @@
+ #!/bin/sh
+-exec run
++exec 'run' --verbose
+`,
+	}, report.Drifts[0])
+	assert.Equal(t, generation.DriftAdded, report.Drifts[1].Kind)
+	assert.Equal(t, "/out/notes.txt", report.Drifts[1].Path)
+	assert.Equal(t, generation.DriftMissing, report.Drifts[2].Kind)
+	assert.Equal(t, "/out/static.txt", report.Drifts[2].Path)
+	assert.Contains(t, report.String(), "missing  /out/static.txt (tool)\n")
+
+	// files in directories that are not owned are ignored
+	report, err = newChecker(fs, false).Check()
+	require.Error(t, err)
+	assert.Len(t, report.Drifts, 2)
+}
+
+func TestChecker_NotGenerated(t *testing.T) {
+	report, err := newChecker(afero.NewMemMapFs(), true).Check()
+	require.Error(t, err)
+	assert.Len(t, report.Drifts, 3)
+	for _, d := range report.Drifts {
+		assert.Equal(t, generation.DriftMissing, d.Kind)
+	}
+}
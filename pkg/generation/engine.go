@@ -0,0 +1,272 @@
+package generation
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+// TemplateExtension marks the files of a template tree that are rendered. The extension
+// is stripped from the output path; all other files are copied verbatim.
+const TemplateExtension = ".tmpl"
+
+// OverwritePolicy decides what happens to existing files with a different content
+type OverwritePolicy int
+
+const (
+	// OverwriteAlways replaces existing files
+	OverwriteAlways OverwritePolicy = iota
+	// OverwriteNever keeps existing files and reports them as skipped
+	OverwriteNever
+	// OverwriteError fails the rendering before any file has been written
+	OverwriteError
+)
+
+// Action describes what the Engine did, or would do in dry-run mode, with a file
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+	ActionSkip      Action = "skip"
+)
+
+// File is a rendered file
+type File struct {
+	// Path is the output path on the Engine's afero.Fs
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+	Action  Action
+}
+
+// Engine renders text/template templates into an afero.Fs
+type Engine struct {
+	fs      afero.Fs
+	paths   env.Paths
+	runtime system.RuntimeInfo
+	funcs   template.FuncMap
+	policy  OverwritePolicy
+	dryRun  bool
+}
+
+type Option func(*Engine) *Engine
+
+// WithPaths sets the env.Paths exposed to templates as paths
+func WithPaths(paths env.Paths) Option {
+	return func(e *Engine) *Engine {
+		e.paths = paths
+		return e
+	}
+}
+
+// WithRuntimeInfo sets the system.RuntimeInfo exposed to templates as runtime
+func WithRuntimeInfo(info system.RuntimeInfo) Option {
+	return func(e *Engine) *Engine {
+		e.runtime = info
+		return e
+	}
+}
+
+// WithFuncs adds funcs to the function map, replacing builtin functions of the same name
+func WithFuncs(funcs template.FuncMap) Option {
+	return func(e *Engine) *Engine {
+		for name, fn := range funcs {
+			e.funcs[name] = fn
+		}
+		return e
+	}
+}
+
+// WithOverwritePolicy sets the OverwritePolicy for existing files
+func WithOverwritePolicy(policy OverwritePolicy) Option {
+	return func(e *Engine) *Engine {
+		e.policy = policy
+		return e
+	}
+}
+
+// WithDryRun makes the Engine report the files it would write without writing them
+func WithDryRun(dryRun bool) Option {
+	return func(e *Engine) *Engine {
+		e.dryRun = dryRun
+		return e
+	}
+}
+
+// NewEngine returns an Engine writing to fs. Unless configured otherwise, templates
+// see the paths returned by env.GetPaths and the runtime info of system.Get.
+func NewEngine(fs afero.Fs, opts ...Option) *Engine {
+	e := &Engine{
+		fs:      fs,
+		runtime: system.Get(),
+		funcs:   template.FuncMap{},
+	}
+	for name, fn := range e.builtins() {
+		e.funcs[name] = fn
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// getPaths returns the paths exposed to templates. Unless configured by WithPaths,
+// they are inferred on first use, so that templates not using them render even if
+// the paths cannot be inferred.
+func (e *Engine) getPaths() (env.Paths, error) {
+	if e.paths.Base() == "" {
+		paths, err := env.GetPaths()
+		if err != nil {
+			return env.Paths{}, err
+		}
+		e.paths = paths
+	}
+	return e.paths, nil
+}
+
+// Fs returns the afero.Fs the Engine writes to
+func (e *Engine) Fs() afero.Fs { return e.fs }
+
+func (e *Engine) template(name string) *template.Template {
+	return template.New(name).Funcs(e.funcs).Option("missingkey=error")
+}
+
+// RenderString renders the template text with data
+func (e *Engine) RenderString(name, text string, data interface{}) (string, error) {
+	t, err := e.template(name).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse template %s", name)
+	}
+	buf := &bytes.Buffer{}
+	if err = t.Execute(buf, data); err != nil {
+		return "", errors.Wrapf(err, "failed to render template %s", name)
+	}
+	return buf.String(), nil
+}
+
+// Render renders the tree of templates below root in src into the directory dst.
+//
+// Paths of the tree may contain template actions as well, e.g. "{{ .Name }}.yaml.tmpl".
+// Nothing is written if any template fails to render or, with OverwriteError, if an
+// existing file would change.
+func (e *Engine) Render(src fs.FS, root, dst string, data interface{}) ([]File, error) {
+	files, err := e.Plan(src, root, dst, data)
+	if err != nil {
+		return nil, err
+	}
+	return files, e.Write(files)
+}
+
+// Plan renders the tree like Render and determines the Action of every file, but does
+// not write anything.
+func (e *Engine) Plan(src fs.FS, root, dst string, data interface{}) ([]File, error) {
+	var files []File
+	err := fs.WalkDir(src, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		if root == "." {
+			rel = name
+		}
+
+		out, err := e.RenderString(name, rel, data)
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(src, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read template %s", name)
+		}
+		if strings.HasSuffix(out, TemplateExtension) {
+			out = strings.TrimSuffix(out, TemplateExtension)
+			rendered, err := e.RenderString(name, string(content), data)
+			if err != nil {
+				return err
+			}
+			content = []byte(rendered)
+		}
+
+		mode := os.FileMode(0644)
+		if info, err := d.Info(); err == nil && info.Mode().Perm()&0111 != 0 {
+			mode = 0755
+		}
+		target := filepath.Join(dst, filepath.FromSlash(path.Clean(out)))
+		if path.IsAbs(out) || !below(dst, target) {
+			return errors.Errorf("template %s renders to %q, which is not below %s", name, out, dst)
+		}
+		files = append(files, File{
+			Path:    target,
+			Content: content,
+			Mode:    mode,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	for i := range files {
+		if files[i].Action, err = e.action(files[i]); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// below returns whether target is a path below dir
+func below(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (e *Engine) action(f File) (Action, error) {
+	current, err := afero.ReadFile(e.fs, f.Path)
+	switch {
+	case os.IsNotExist(err):
+		return ActionCreate, nil
+	case err != nil:
+		return "", errors.Wrapf(err, "failed to read %s", f.Path)
+	case bytes.Equal(current, f.Content):
+		return ActionUnchanged, nil
+	}
+
+	switch e.policy {
+	case OverwriteNever:
+		return ActionSkip, nil
+	case OverwriteError:
+		return "", errors.Errorf("refusing to overwrite %s", f.Path)
+	default:
+		return ActionUpdate, nil
+	}
+}
+
+// Write writes the created and updated files. In dry-run mode nothing is written.
+func (e *Engine) Write(files []File) error {
+	if e.dryRun {
+		return nil
+	}
+	for _, f := range files {
+		if f.Action != ActionCreate && f.Action != ActionUpdate {
+			continue
+		}
+		if err := env.WriteFileAtomic(e.fs, f.Path, f.Content, f.Mode); err != nil {
+			return errors.Wrapf(err, "failed to write %s", f.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package generation_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/generation"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+var templates = fstest.MapFS{
+	"tmpl/config/{{ kebab .Name }}.yaml.tmpl": {Data: []byte("# {{ .Name }}\nname: {{ snake .Name }}\nbin: {{ paths.Bin .Name }}\nplatform: {{ platform \"[os]-[arch]\" }}\n")},
+	"tmpl/bin/run.sh.tmpl":                    {Data: []byte("#!/bin/sh\nexec {{ quote .Command }}\n"), Mode: 0755},
+	"tmpl/static.txt":                         {Data: []byte("{{ not rendered }}\n")},
+}
+
+type data struct {
+	Name    string
+	Command string
+}
+
+func newEngine(fs afero.Fs, opts ...generation.Option) *generation.Engine {
+	opts = append([]generation.Option{
+		generation.WithPaths(env.NewPaths("/devctl")),
+		generation.WithRuntimeInfo(system.RuntimeInfo{OS: "linux", Arch: "amd64"}),
+	}, opts...)
+	return generation.NewEngine(fs, opts...)
+}
+
+func read(t *testing.T, fs afero.Fs, path string) string {
+	b, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestEngine_Render(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files, err := newEngine(fs).Render(templates, "tmpl", "/out", data{Name: "MyTool", Command: "my tool"})
+	require.NoError(t, err)
+
+	require.Len(t, files, 3)
+	for _, f := range files {
+		assert.Equal(t, generation.ActionCreate, f.Action, f.Path)
+	}
+	assert.Equal(t, "# MyTool\nname: my_tool\nbin: /devctl/bin/MyTool\nplatform: linux-amd64\n", read(t, fs, "/out/config/my-tool.yaml"))
+	assert.Equal(t, "#!/bin/sh\nexec 'my tool'\n", read(t, fs, "/out/bin/run.sh"))
+	assert.Equal(t, "{{ not rendered }}\n", read(t, fs, "/out/static.txt"))
+
+	fi, err := fs.Stat("/out/bin/run.sh")
+	require.NoError(t, err)
+	assert.Equal(t, "-rwxr-xr-x", fi.Mode().Perm().String())
+}
+
+func TestEngine_OverwritePolicy(t *testing.T) {
+	tcs := []struct {
+		name     string
+		policy   generation.OverwritePolicy
+		action   generation.Action
+		expected string
+		err      bool
+	}{
+		{"always", generation.OverwriteAlways, generation.ActionUpdate, "{{ not rendered }}\n", false},
+		{"never", generation.OverwriteNever, generation.ActionSkip, "edited\n", false},
+		{"error", generation.OverwriteError, "", "edited\n", true},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/out/static.txt", []byte("edited\n"), 0644))
+
+			files, err := newEngine(fs, generation.WithOverwritePolicy(tt.policy)).Render(templates, "tmpl", "/out", data{Name: "x"})
+			assert.Equal(t, tt.expected, read(t, fs, "/out/static.txt"))
+			if tt.err {
+				assert.Error(t, err)
+				exists, _ := afero.Exists(fs, "/out/config/x.yaml")
+				assert.False(t, exists, "nothing must be written")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.action, files[2].Action)
+		})
+	}
+}
+
+func TestEngine_DryRun(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files, err := newEngine(fs, generation.WithDryRun(true)).Render(templates, "tmpl", "/out", data{Name: "x"})
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	exists, _ := afero.DirExists(fs, "/out")
+	assert.False(t, exists)
+}
+
+func TestEngine_PathOutsideDst(t *testing.T) {
+	for _, name := range []string{"../x", "/etc/x", "a/../../x", "."} {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			src := fstest.MapFS{"tmpl/{{ .Name }}": {Data: []byte("x")}, "tmpl/static.txt": {Data: []byte("x")}}
+			_, err := newEngine(fs).Render(src, "tmpl", "/out/dir", data{Name: name})
+			assert.EqualError(t, err, "template tmpl/{{ .Name }} renders to \""+name+"\", which is not below /out/dir")
+			exists, _ := afero.Exists(fs, "/out")
+			assert.False(t, exists, "nothing must be written")
+		})
+	}
+}
+
+func TestEngine_InvalidPaths(t *testing.T) {
+	t.Setenv("DEVCTL_ROOT", "")
+	t.Setenv("DEVCTL_LAYOUT", "invalid")
+	e := generation.NewEngine(afero.NewMemMapFs())
+
+	out, err := e.RenderString("t", "{{ .Name }}", data{Name: "plain"})
+	require.NoError(t, err, "the paths are only inferred if they are used")
+	assert.Equal(t, "plain", out)
+
+	_, err = e.RenderString("t", "{{ paths.Bin .Name }}", data{Name: "go"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown layout DEVCTL_LAYOUT=invalid")
+}
+
+func TestEngine_RenderString(t *testing.T) {
+	e := newEngine(afero.NewMemMapFs())
+
+	out, err := e.RenderString("t", `{{ comment "go" "hi" }} {{ shellQuote "fish" "it's" }} {{ runtime.OS }} {{ indent 2 "a\nb" }}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `// hi 'it\'s' linux   a
+  b`, out)
+
+	out, err = e.RenderString("t", `{{ banner "x" "yaml" }}`, nil)
+	require.NoError(t, err)
+	assert.Contains(t, out, "# ")
+
+	_, err = e.RenderString("t", `{{ comment "cobol" "x" }}`, nil)
+	assert.Error(t, err)
+	_, err = e.RenderString("t", `{{ .Missing }}`, map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestCaseConversion(t *testing.T) {
+	tcs := []struct {
+		in, camel, pascal, snake, screaming, kebab string
+	}{
+		{"devctl kit", "devctlKit", "DevctlKit", "devctl_kit", "DEVCTL_KIT", "devctl-kit"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server", "HTTP_SERVER", "http-server"},
+		{"go-sdk_v2", "goSdkV2", "GoSdkV2", "go_sdk_v2", "GO_SDK_V2", "go-sdk-v2"},
+		{"myTool", "myTool", "MyTool", "my_tool", "MY_TOOL", "my-tool"},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.camel, generation.CamelCase(tt.in))
+			assert.Equal(t, tt.pascal, generation.PascalCase(tt.in))
+			assert.Equal(t, tt.snake, generation.SnakeCase(tt.in))
+			assert.Equal(t, tt.screaming, generation.ScreamingSnakeCase(tt.in))
+			assert.Equal(t, tt.kebab, generation.KebabCase(tt.in))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package generation
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+	"github.com/alex-held/devctl-kit/pkg/generation/shell"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+// builtins returns the functions available to every template
+//
+//	banner "title" "shell"        banner.GenerateBanner
+//	comment "shell" "text"        banner.Comment
+//	runtime                       system.RuntimeInfo, e.g. {{ runtime.OS }}
+//	platform "[os]-[arch].tgz"    system.RuntimeInfo.Format
+//	paths                         env.Paths, e.g. {{ paths.Bin "go" }}
+//	quote, doubleQuote            POSIX shell quoting
+//	shellQuote "fish" "value"     quoting of a shell.Shell
+//	lower, upper, camel, pascal, snake, screamingSnake, kebab
+//	indent 2 "text", trim, join
+func (e *Engine) builtins() template.FuncMap {
+	return template.FuncMap{
+		"banner": func(text string, kind ...string) (string, error) {
+			k := banner.KIND_SHELL
+			if len(kind) > 0 {
+				var err error
+				if k, err = banner.ParseKind(kind[0]); err != nil {
+					return "", err
+				}
+			}
+			return banner.GenerateBanner(text, k), nil
+		},
+		"comment": func(kind, text string) (string, error) {
+			k, err := banner.ParseKind(kind)
+			if err != nil {
+				return "", err
+			}
+			return banner.Comment(k, text), nil
+		},
+		"runtime":  func() system.RuntimeInfo { return e.runtime },
+		"platform": func(pattern string) string { return e.runtime.Format(pattern) },
+		"paths":    e.getPaths,
+
+		"quote":       shell.Quote,
+		"doubleQuote": shell.DoubleQuote,
+		"shellQuote": func(name, value string) (string, error) {
+			sh, err := shell.ParseShell(name)
+			if err != nil {
+				return "", err
+			}
+			return sh.Quote(value), nil
+		},
+
+		"lower":          strings.ToLower,
+		"upper":          strings.ToUpper,
+		"camel":          CamelCase,
+		"pascal":         PascalCase,
+		"snake":          SnakeCase,
+		"screamingSnake": ScreamingSnakeCase,
+		"kebab":          KebabCase,
+
+		"indent": indent,
+		"trim":   strings.TrimSpace,
+		"join":   func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	}
+}
+
+// indent indents every non-empty line of text by n spaces
+func indent(n int, text string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
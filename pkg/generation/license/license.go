@@ -0,0 +1,326 @@
+package license
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+// Mode selects what Run does with the files of a source tree
+type Mode int
+
+const (
+	// ModeCheck reports files with missing headers, or outdated ones which ModeUpdate
+	// would change, without modifying them
+	ModeCheck Mode = iota
+	// ModeAdd adds headers to files without one
+	ModeAdd
+	// ModeUpdate adds missing headers and updates the identifier, holder and year range
+	// of existing ones
+	ModeUpdate
+)
+
+// Status is the license header status of a file
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusMissing  Status = "missing"
+	StatusOutdated Status = "outdated"
+	StatusAdded    Status = "added"
+	StatusUpdated  Status = "updated"
+)
+
+// headerScanLines is the number of lines searched for an existing header
+const headerScanLines = 20
+
+// skippedDirs contains directories holding vendored, generated or fixture files
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"vendor":       true,
+	"node_modules": true,
+	"third_party":  true,
+	"testdata":     true,
+}
+
+var (
+	generatedRe = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$|@generated`)
+	spdxRe      = regexp.MustCompile(`SPDX-License-Identifier:\s*(\S+)`)
+	copyrightRe = regexp.MustCompile(`Copyright(?:\s+\([cC]\))?\s+(\d{4})(?:\s*[-,]\s*\d{4})?\s+(.+?)\s*$`)
+)
+
+// Config configures the license header of a source tree
+type Config struct {
+	// License is the SPDX license identifier, e.g. MIT or Apache-2.0
+	License string
+	// Holder is the copyright holder
+	Holder string
+	// Year is the current year, defaults to the year of time.Now
+	Year int
+	// Exclude contains glob patterns matched against the slash separated path relative
+	// to the root and against the base name of every file
+	Exclude []string
+}
+
+// FileResult is the status of a single file
+type FileResult struct {
+	Path   string
+	Status Status
+}
+
+// Report lists the files with a header related status, sorted by path
+type Report struct {
+	Files []FileResult
+}
+
+// Issues returns the files with a missing or outdated header
+func (r *Report) Issues() (issues []FileResult) {
+	for _, f := range r.Files {
+		if f.Status == StatusMissing || f.Status == StatusOutdated {
+			issues = append(issues, f)
+		}
+	}
+	return issues
+}
+
+// Manager adds, checks and updates license headers on an afero.Fs
+type Manager struct {
+	fs  afero.Fs
+	cfg Config
+}
+
+// NewManager returns a Manager for the files on fs
+func NewManager(fs afero.Fs, cfg Config) *Manager {
+	if cfg.Year == 0 {
+		cfg.Year = time.Now().Year()
+	}
+	return &Manager{fs: fs, cfg: cfg}
+}
+
+// Run walks the tree below root and processes every file with a known comment syntax.
+// In ModeCheck an *constants.ExitError with constants.IssuesFound is returned if any
+// file has a missing or outdated header.
+func (m *Manager) Run(root string, mode Mode) (*Report, error) {
+	if m.cfg.License == "" {
+		return nil, errors.New("license: no SPDX license identifier configured")
+	}
+
+	report := &Report{}
+	err := afero.Walk(m.fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		if info.IsDir() {
+			if path != root && (skippedDirs[info.Name()] || m.excluded(rel, info.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || m.excluded(rel, info.Name()) {
+			return nil
+		}
+		kind, ok := banner.KindForFile(path)
+		if !ok {
+			return nil
+		}
+
+		status, err := m.process(path, info.Mode().Perm(), kind, mode)
+		if err != nil {
+			return err
+		}
+		if status != "" {
+			report.Files = append(report.Files, FileResult{Path: path, Status: status})
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	sort.Slice(report.Files, func(i, j int) bool { return report.Files[i].Path < report.Files[j].Path })
+
+	if issues := report.Issues(); mode == ModeCheck && len(issues) > 0 {
+		return report, &constants.ExitError{
+			ExitCode: constants.IssuesFound,
+			Message:  fmt.Sprintf("%d files with missing or outdated license headers", len(issues)),
+		}
+	}
+	return report, nil
+}
+
+func (m *Manager) excluded(rel, name string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range m.cfg.Exclude {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// header is an existing license header
+type header struct {
+	license   string
+	holder    string
+	firstYear int
+	lines     []int
+}
+
+// process checks, adds or updates the header of a single file. Generated files
+// return an empty Status.
+func (m *Manager) process(path string, perm os.FileMode, kind banner.OutputKind, mode Mode) (Status, error) {
+	b, err := afero.ReadFile(m.fs, path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", path)
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	if isGenerated(lines) {
+		return "", nil
+	}
+
+	// a header is outdated if ModeUpdate would change it, e.g. its year range
+	h := parseHeader(lines, kind)
+	status, updated := StatusMissing, ""
+	if h != nil {
+		first := m.cfg.Year
+		if h.firstYear > 0 && h.firstYear < first {
+			first = h.firstYear
+		}
+		status, updated = StatusOK, replaceHeader(lines, h, m.render(kind, first))
+		if updated != string(b) {
+			status = StatusOutdated
+		}
+	}
+
+	switch {
+	case mode == ModeCheck:
+		return status, nil
+	case status == StatusMissing:
+		if err = m.write(path, perm, insertHeader(lines, m.render(kind, m.cfg.Year))); err != nil {
+			return "", err
+		}
+		return StatusAdded, nil
+	case mode == ModeUpdate && status == StatusOutdated:
+		if err = m.write(path, perm, updated); err != nil {
+			return "", err
+		}
+		return StatusUpdated, nil
+	default:
+		return status, nil
+	}
+}
+
+func (m *Manager) write(path string, perm os.FileMode, content string) error {
+	if err := env.WriteFileAtomic(m.fs, path, []byte(content), perm); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return nil
+}
+
+// render returns the header lines for kind, with a copyright range starting at firstYear
+func (m *Manager) render(kind banner.OutputKind, firstYear int) []string {
+	lines := []string{banner.Comment(kind, "SPDX-License-Identifier: "+m.cfg.License) + "\n"}
+	if m.cfg.Holder != "" {
+		years := strconv.Itoa(m.cfg.Year)
+		if firstYear < m.cfg.Year {
+			years = fmt.Sprintf("%d-%d", firstYear, m.cfg.Year)
+		}
+		lines = append(lines, banner.Comment(kind, fmt.Sprintf("Copyright %s %s", years, m.cfg.Holder))+"\n")
+	}
+	return lines
+}
+
+// isGenerated returns whether the first lines contain the Go generated code comment
+// or an @generated tag
+func isGenerated(lines []string) bool {
+	for i, line := range lines {
+		if i >= headerScanLines {
+			break
+		}
+		if generatedRe.MatchString(strings.TrimRight(line, "\r\n")) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHeader finds the SPDX and copyright comment lines in the first lines of a file
+func parseHeader(lines []string, kind banner.OutputKind) *header {
+	prefix := strings.TrimSpace(banner.CommentPrefix(kind))
+	var h *header
+	for i, line := range lines {
+		if i >= headerScanLines {
+			break
+		}
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, prefix) {
+			continue
+		}
+		text := strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(trimmed, prefix)), strings.TrimSpace(banner.CommentSuffix(kind)))
+		if m := spdxRe.FindStringSubmatch(text); m != nil {
+			if h == nil {
+				h = &header{}
+			}
+			h.license = m[1]
+			h.lines = append(h.lines, i)
+		} else if m := copyrightRe.FindStringSubmatch(strings.TrimSpace(text)); m != nil {
+			if h == nil {
+				h = &header{}
+			}
+			h.firstYear, _ = strconv.Atoi(m[1])
+			h.holder = m[2]
+			h.lines = append(h.lines, i)
+		}
+	}
+	if h == nil || h.license == "" {
+		return nil
+	}
+	return h
+}
+
+// insertHeader inserts the header after a shebang or XML declaration
+func insertHeader(lines []string, header []string) string {
+	pos := 0
+	if len(lines) > 0 && (strings.HasPrefix(lines[0], "#!") || strings.HasPrefix(lines[0], "<?xml")) {
+		pos = 1
+	}
+	out := append([]string{}, lines[:pos]...)
+	out = append(out, header...)
+	if rest := strings.Join(lines[pos:], ""); rest != "" {
+		out = append(out, "\n", rest)
+	}
+	return strings.Join(out, "")
+}
+
+// replaceHeader replaces the lines of h with header
+func replaceHeader(lines []string, h *header, header []string) string {
+	skip := map[int]bool{}
+	for _, i := range h.lines {
+		skip[i] = true
+	}
+	out := make([]string, 0, len(lines)+len(header))
+	for i, line := range lines {
+		if i == h.lines[0] {
+			out = append(out, header...)
+		}
+		if !skip[i] {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "")
+}
@@ -0,0 +1,147 @@
+package license_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/generation/license"
+)
+
+func newFs(t *testing.T, files map[string]string) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	return fs
+}
+
+func read(t *testing.T, fs afero.Fs, path string) string {
+	b, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+var cfg = license.Config{License: "MIT", Holder: "Alex Held", Year: 2021}
+
+func TestManager_Add(t *testing.T) {
+	fs := newFs(t, map[string]string{
+		"/repo/main.go":             "package main\n",
+		"/repo/hack/build.sh":       "#!/usr/bin/env bash\necho build\n",
+		"/repo/config.yaml":         "key: value\n",
+		"/repo/query.sql":           "SELECT 1;\n",
+		"/repo/site/index.html":     "<html></html>\n",
+		"/repo/icon.svg":            "<?xml version=\"1.0\"?>\n<svg/>\n",
+		"/repo/zz_generated.go":     "// Code generated by tool. DO NOT EDIT.\npackage main\n",
+		"/repo/vendor/lib/lib.go":   "package lib\n",
+		"/repo/testdata/x.go":       "package x\n",
+		"/repo/README.md":           "# readme\n",
+		"/repo/internal/skip_me.go": "package internal\n",
+	})
+	c := cfg
+	c.Exclude = []string{"internal/skip_*"}
+
+	report, err := license.NewManager(fs, c).Run("/repo", license.ModeAdd)
+	require.NoError(t, err)
+	assert.Len(t, report.Files, 6)
+	for _, f := range report.Files {
+		assert.Equal(t, license.StatusAdded, f.Status, f.Path)
+	}
+
+	assert.Equal(t, "// SPDX-License-Identifier: MIT\n// Copyright 2021 Alex Held\n\npackage main\n", read(t, fs, "/repo/main.go"))
+	assert.Equal(t, "#!/usr/bin/env bash\n# SPDX-License-Identifier: MIT\n# Copyright 2021 Alex Held\n\necho build\n", read(t, fs, "/repo/hack/build.sh"))
+	assert.Equal(t, "-- SPDX-License-Identifier: MIT\n-- Copyright 2021 Alex Held\n\nSELECT 1;\n", read(t, fs, "/repo/query.sql"))
+	assert.Equal(t, "<!-- SPDX-License-Identifier: MIT -->\n<!-- Copyright 2021 Alex Held -->\n\n<html></html>\n", read(t, fs, "/repo/site/index.html"))
+	assert.Equal(t, "<?xml version=\"1.0\"?>\n<!-- SPDX-License-Identifier: MIT -->\n<!-- Copyright 2021 Alex Held -->\n\n<svg/>\n", read(t, fs, "/repo/icon.svg"))
+
+	for path, content := range map[string]string{
+		"/repo/zz_generated.go":     "// Code generated by tool. DO NOT EDIT.\npackage main\n",
+		"/repo/vendor/lib/lib.go":   "package lib\n",
+		"/repo/testdata/x.go":       "package x\n",
+		"/repo/README.md":           "# readme\n",
+		"/repo/internal/skip_me.go": "package internal\n",
+	} {
+		assert.Equal(t, content, read(t, fs, path), path)
+	}
+
+	// running again is a no-op
+	report, err = license.NewManager(fs, c).Run("/repo", license.ModeAdd)
+	require.NoError(t, err)
+	for _, f := range report.Files {
+		assert.Equal(t, license.StatusOK, f.Status, f.Path)
+	}
+}
+
+func TestManager_Generated(t *testing.T) {
+	fs := newFs(t, map[string]string{
+		"/repo/generated.go": "// Code generated by tool. DO NOT EDIT.\n\npackage main\n",
+		"/repo/tagged.sh":    "# @generated by tool\necho\n",
+		"/repo/edit.go":      "// Do not edit this function without updating the docs\npackage main\n",
+		"/repo/lower.go":     "// code generated by tool, do not edit\npackage main\n",
+	})
+
+	report, err := license.NewManager(fs, cfg).Run("/repo", license.ModeCheck)
+	require.Error(t, err)
+	assert.Equal(t, []license.FileResult{
+		{Path: "/repo/edit.go", Status: license.StatusMissing},
+		{Path: "/repo/lower.go", Status: license.StatusMissing},
+	}, report.Files)
+}
+
+func TestManager_Check(t *testing.T) {
+	fs := newFs(t, map[string]string{
+		"/repo/ok.go":       "// SPDX-License-Identifier: MIT\n// Copyright 2019-2021 Alex Held\n\npackage ok\n",
+		"/repo/missing.go":  "package missing\n",
+		"/repo/outdated.go": "// SPDX-License-Identifier: Apache-2.0\n// Copyright 2020 Alex Held\n\npackage outdated\n",
+		"/repo/years.go":    "// SPDX-License-Identifier: MIT\n// Copyright 2019-2020 Alex Held\n\npackage years\n",
+	})
+
+	report, err := license.NewManager(fs, cfg).Run("/repo", license.ModeCheck)
+	require.Error(t, err)
+	exitErr, ok := err.(*constants.ExitError)
+	require.True(t, ok)
+	assert.Equal(t, constants.IssuesFound, int(exitErr.ExitCode))
+
+	assert.Equal(t, []license.FileResult{
+		{Path: "/repo/missing.go", Status: license.StatusMissing},
+		{Path: "/repo/ok.go", Status: license.StatusOK},
+		{Path: "/repo/outdated.go", Status: license.StatusOutdated},
+		{Path: "/repo/years.go", Status: license.StatusOutdated},
+	}, report.Files)
+	assert.Len(t, report.Issues(), 3)
+	assert.Equal(t, "package missing\n", read(t, fs, "/repo/missing.go"))
+
+	// check passes once update fixed every issue
+	_, err = license.NewManager(fs, cfg).Run("/repo", license.ModeUpdate)
+	require.NoError(t, err)
+	report, err = license.NewManager(fs, cfg).Run("/repo", license.ModeCheck)
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues())
+}
+
+func TestManager_Update(t *testing.T) {
+	fs := newFs(t, map[string]string{
+		"/repo/a.go":   "// SPDX-License-Identifier: Apache-2.0\n// Copyright (c) 2019 Someone Else\n\npackage a\n",
+		"/repo/b.go":   "// SPDX-License-Identifier: MIT\n// Copyright 2021 Alex Held\n\npackage b\n",
+		"/repo/c.yaml": "# SPDX-License-Identifier: MIT\n# Copyright 2018-2019 Alex Held\nkey: value\n",
+	})
+
+	report, err := license.NewManager(fs, cfg).Run("/repo", license.ModeUpdate)
+	require.NoError(t, err)
+	assert.Equal(t, []license.FileResult{
+		{Path: "/repo/a.go", Status: license.StatusUpdated},
+		{Path: "/repo/b.go", Status: license.StatusOK},
+		{Path: "/repo/c.yaml", Status: license.StatusUpdated},
+	}, report.Files)
+
+	assert.Equal(t, "// SPDX-License-Identifier: MIT\n// Copyright 2019-2021 Alex Held\n\npackage a\n", read(t, fs, "/repo/a.go"))
+	assert.Equal(t, "# SPDX-License-Identifier: MIT\n# Copyright 2018-2021 Alex Held\nkey: value\n", read(t, fs, "/repo/c.yaml"))
+}
+
+func TestManager_RequiresLicense(t *testing.T) {
+	_, err := license.NewManager(afero.NewMemMapFs(), license.Config{}).Run("/", license.ModeCheck)
+	assert.Error(t, err)
+}
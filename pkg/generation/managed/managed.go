@@ -0,0 +1,275 @@
+package managed
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+	"github.com/alex-held/devctl-kit/pkg/generation/diff"
+)
+
+const (
+	// DefaultName is the name used in the markers of managed blocks
+	DefaultName = "devctl"
+	// DefaultBackupSuffix is appended to the path of a file to get the path of its backup
+	DefaultBackupSuffix = ".devctl.bak"
+)
+
+// Editor inserts, updates and removes a managed block in text files, e.g. ~/.zshrc.
+//
+// The block is delimited by the comment lines "- - - BEGIN {name} - - -" and
+// "- - - END {name} - - -".
+// Content outside of the block is never modified. Files are written atomically by
+// renaming a temporary file next to the target and the previous version of a file
+// is kept as a backup. Symbolic links are followed, the file they point to is written
+// and backed up.
+type Editor struct {
+	fs           afero.Fs
+	name         string
+	kind         banner.OutputKind
+	backupSuffix string
+	dryRun       bool
+}
+
+type Option func(*Editor) *Editor
+
+// WithName sets the name of the block markers
+func WithName(name string) Option {
+	return func(e *Editor) *Editor {
+		e.name = name
+		return e
+	}
+}
+
+// WithKind sets the comment syntax of the block markers
+func WithKind(kind banner.OutputKind) Option {
+	return func(e *Editor) *Editor {
+		e.kind = kind
+		return e
+	}
+}
+
+// WithBackupSuffix sets the suffix of backup files. An empty suffix disables backups.
+func WithBackupSuffix(suffix string) Option {
+	return func(e *Editor) *Editor {
+		e.backupSuffix = suffix
+		return e
+	}
+}
+
+// WithDryRun makes the Editor report the changes without writing any files
+func WithDryRun(dryRun bool) Option {
+	return func(e *Editor) *Editor {
+		e.dryRun = dryRun
+		return e
+	}
+}
+
+// NewEditor returns an Editor operating on fs
+func NewEditor(fs afero.Fs, opts ...Option) *Editor {
+	e := &Editor{
+		fs:           fs,
+		name:         DefaultName,
+		kind:         banner.KIND_SHELL,
+		backupSuffix: DefaultBackupSuffix,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Result describes the outcome of an edit
+type Result struct {
+	Path string
+	// Changed is true if the content of the file differs from the previous content
+	Changed bool
+	// Created is true if the file did not exist before
+	Created bool
+	// Backup is the path of the backup of the previous content, if one was written
+	Backup string
+	// Diff is the unified diff between the previous and the new content
+	Diff string
+}
+
+func (e *Editor) beginMarker() string { return "BEGIN " + e.name }
+func (e *Editor) endMarker() string   { return "END " + e.name }
+
+// markerLine returns the comment line of marker, without the trailing newline
+func (e *Editor) markerLine(marker string) string {
+	return banner.Comment(e.kind, fmt.Sprintf("- - - - - - - - %s - - - - - - - -", marker))
+}
+
+// noteLine returns the comment line following the begin marker
+func (e *Editor) noteLine() string {
+	return banner.Comment(e.kind, fmt.Sprintf("Managed by %s. Changes inside of this block will be overwritten.", e.name))
+}
+
+// block renders content between the markers
+func (e *Editor) block(content string) string {
+	sb := &strings.Builder{}
+	sb.WriteString(e.markerLine(e.beginMarker()) + "\n")
+	sb.WriteString(e.noteLine() + "\n")
+	if content != "" {
+		sb.WriteString(strings.TrimRight(content, "\n") + "\n")
+	}
+	sb.WriteString(e.markerLine(e.endMarker()) + "\n")
+	return sb.String()
+}
+
+// locate returns the line indices of the begin and end markers, or -1 if there is no
+// block. Only lines equal to the marker lines rendered by block, ignoring surrounding
+// whitespace, are markers, so that user lines mentioning the markers are left alone.
+func (e *Editor) locate(path string, lines []string) (begin, end int, err error) {
+	begin, end = -1, -1
+	beginLine, endLine := strings.TrimSpace(e.markerLine(e.beginMarker())), strings.TrimSpace(e.markerLine(e.endMarker()))
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case beginLine:
+			if begin >= 0 {
+				return -1, -1, errors.Errorf("%s: line %d: duplicate %q marker", path, i+1, e.beginMarker())
+			}
+			begin = i
+		case endLine:
+			if begin < 0 || end >= 0 {
+				return -1, -1, errors.Errorf("%s: line %d: unexpected %q marker", path, i+1, e.endMarker())
+			}
+			end = i
+		}
+	}
+	if begin >= 0 && end < 0 {
+		return -1, -1, errors.Errorf("%s: line %d: %q marker is never closed", path, begin+1, e.beginMarker())
+	}
+	return begin, end, nil
+}
+
+// Read returns the content of the managed block in path
+func (e *Editor) Read(path string) (content string, found bool, err error) {
+	current, _, err := e.read(path)
+	if err != nil {
+		return "", false, err
+	}
+	lines := strings.SplitAfter(current, "\n")
+	begin, end, err := e.locate(path, lines)
+	if err != nil || begin < 0 {
+		return "", false, err
+	}
+	// skip the note following the begin marker
+	inner := lines[begin+1 : end]
+	if len(inner) > 0 && strings.TrimSpace(inner[0]) == strings.TrimSpace(e.noteLine()) {
+		inner = inner[1:]
+	}
+	return strings.Join(inner, ""), true, nil
+}
+
+// Apply inserts the managed block with content into path or replaces the content of
+// an existing block. Files that do not exist are created.
+func (e *Editor) Apply(path, content string) (*Result, error) {
+	current, exists, err := e.read(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.SplitAfter(current, "\n")
+	begin, end, err := e.locate(path, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated string
+	switch {
+	case begin >= 0:
+		updated = strings.Join(lines[:begin], "") + e.block(content) + strings.Join(lines[end+1:], "")
+	case current == "":
+		updated = e.block(content)
+	default:
+		prefix := current
+		if !strings.HasSuffix(prefix, "\n") {
+			prefix += "\n"
+		}
+		updated = prefix + "\n" + e.block(content)
+	}
+	return e.write(path, current, updated, exists)
+}
+
+// Remove removes the managed block from path, including the blank line separating it
+// from the preceding content.
+func (e *Editor) Remove(path string) (*Result, error) {
+	current, exists, err := e.read(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.SplitAfter(current, "\n")
+	begin, end, err := e.locate(path, lines)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || begin < 0 {
+		return &Result{Path: path}, nil
+	}
+
+	if begin > 0 && strings.TrimSpace(lines[begin-1]) == "" {
+		begin--
+	}
+	updated := strings.Join(lines[:begin], "") + strings.Join(lines[end+1:], "")
+	return e.write(path, current, updated, exists)
+}
+
+func (e *Editor) read(path string) (content string, exists bool, err error) {
+	b, err := afero.ReadFile(e.fs, path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to read %s", path)
+	}
+	return string(b), true, nil
+}
+
+func (e *Editor) write(path, current, updated string, exists bool) (*Result, error) {
+	res := &Result{
+		Path:    path,
+		Changed: current != updated || !exists,
+		Created: !exists,
+	}
+	if !res.Changed {
+		return res, nil
+	}
+
+	from := path
+	if !exists {
+		from = "/dev/null"
+	}
+	res.Diff = diff.Unified(from, path, current, updated)
+	if e.dryRun {
+		return res, nil
+	}
+
+	// write through symbolic links, e.g. into a dotfiles repository, rather than
+	// replacing them with a regular file
+	target := path
+	mode := os.FileMode(0644)
+	if exists {
+		var err error
+		if target, err = env.EvalSymlinks(e.fs, path); err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve %s", path)
+		}
+		if fi, err := e.fs.Stat(target); err == nil {
+			mode = fi.Mode().Perm()
+		}
+		if e.backupSuffix != "" {
+			res.Backup = target + e.backupSuffix
+			if err := env.WriteFileAtomic(e.fs, res.Backup, []byte(current), mode); err != nil {
+				return nil, errors.Wrapf(err, "failed to back up %s", path)
+			}
+		}
+	}
+	if err := env.WriteFileAtomic(e.fs, target, []byte(updated), mode); err != nil {
+		return nil, errors.Wrapf(err, "failed to write %s", path)
+	}
+	return res, nil
+}
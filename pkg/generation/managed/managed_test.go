@@ -0,0 +1,179 @@
+package managed_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/generation/managed"
+)
+
+const zshrc = "/home/user/.zshrc"
+
+const userContent = `export EDITOR=vim
+alias ll='ls -la'
+`
+
+func setup(t *testing.T, content string) afero.Fs {
+	fs := afero.NewMemMapFs()
+	if content != "" {
+		require.NoError(t, afero.WriteFile(fs, zshrc, []byte(content), 0600))
+	}
+	return fs
+}
+
+func read(t *testing.T, fs afero.Fs, path string) string {
+	b, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestEditor_Apply(t *testing.T) {
+	fs := setup(t, userContent)
+	e := managed.NewEditor(fs)
+
+	res, err := e.Apply(zshrc, `export PATH="$HOME/.devctl/bin:$PATH"`)
+	require.NoError(t, err)
+	assert.True(t, res.Changed)
+	assert.False(t, res.Created)
+	assert.Equal(t, zshrc+managed.DefaultBackupSuffix, res.Backup)
+	assert.Equal(t, userContent, read(t, fs, res.Backup))
+
+	expected := userContent + `
+# - - - - - - - - BEGIN devctl - - - - - - - -
+# Managed by devctl. Changes inside of this block will be overwritten.
+export PATH="$HOME/.devctl/bin:$PATH"
+# - - - - - - - - END devctl - - - - - - - -
+`
+	assert.Equal(t, expected, read(t, fs, zshrc))
+
+	fi, err := fs.Stat(zshrc)
+	require.NoError(t, err)
+	assert.Equal(t, "-rw-------", fi.Mode().Perm().String())
+
+	content, found, err := e.Read(zshrc)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "export PATH=\"$HOME/.devctl/bin:$PATH\"\n", content)
+
+	t.Run("idempotent", func(t *testing.T) {
+		res, err := e.Apply(zshrc, `export PATH="$HOME/.devctl/bin:$PATH"`)
+		require.NoError(t, err)
+		assert.False(t, res.Changed)
+		assert.Equal(t, expected, read(t, fs, zshrc))
+	})
+
+	t.Run("update keeps user content", func(t *testing.T) {
+		require.NoError(t, afero.WriteFile(fs, zshrc, []byte(read(t, fs, zshrc)+"alias k=kubectl\n"), 0600))
+
+		_, err := e.Apply(zshrc, "source ~/.devctl/init.zsh")
+		require.NoError(t, err)
+		actual := read(t, fs, zshrc)
+		assert.Contains(t, actual, "source ~/.devctl/init.zsh\n")
+		assert.NotContains(t, actual, "export PATH")
+		assert.Contains(t, actual, userContent)
+		assert.Contains(t, actual, "END devctl - - - - - - - -\nalias k=kubectl\n")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		res, err := e.Remove(zshrc)
+		require.NoError(t, err)
+		assert.True(t, res.Changed)
+		assert.Equal(t, userContent+"alias k=kubectl\n", read(t, fs, zshrc))
+	})
+}
+
+func TestEditor_Apply_CreatesFile(t *testing.T) {
+	fs := setup(t, "")
+	res, err := managed.NewEditor(fs).Apply(zshrc, "echo hi")
+	require.NoError(t, err)
+	assert.True(t, res.Created)
+	assert.Empty(t, res.Backup)
+	assert.Contains(t, read(t, fs, zshrc), "echo hi\n")
+}
+
+func TestEditor_Apply_Symlink(t *testing.T) {
+	fs := envtest.NewSymlinkFs()
+	dotfile := "/home/user/dotfiles/zshrc"
+	require.NoError(t, afero.WriteFile(fs, dotfile, []byte(userContent), 0600))
+	require.NoError(t, fs.SymlinkIfPossible("dotfiles/zshrc", zshrc))
+
+	res, err := managed.NewEditor(fs).Apply(zshrc, "echo hi")
+	require.NoError(t, err)
+	assert.Equal(t, dotfile+managed.DefaultBackupSuffix, res.Backup)
+	assert.Equal(t, userContent, read(t, fs, res.Backup))
+
+	link, err := env.IsSymlink(fs, zshrc)
+	require.NoError(t, err)
+	assert.True(t, link, "the link is kept")
+	assert.Contains(t, read(t, fs, dotfile), "echo hi\n")
+}
+
+func TestEditor_DryRun(t *testing.T) {
+	fs := setup(t, userContent)
+	res, err := managed.NewEditor(fs, managed.WithDryRun(true)).Apply(zshrc, "echo hi")
+	require.NoError(t, err)
+
+	assert.True(t, res.Changed)
+	assert.Equal(t, userContent, read(t, fs, zshrc))
+	assert.Contains(t, res.Diff, "--- "+zshrc+"\n+++ "+zshrc+"\n")
+	assert.Contains(t, res.Diff, "+echo hi\n")
+	exists, _ := afero.Exists(fs, zshrc+managed.DefaultBackupSuffix)
+	assert.False(t, exists)
+}
+
+func TestEditor_InvalidMarkers(t *testing.T) {
+	begin, end := "# - - - - - - - - BEGIN devctl - - - - - - - -\n", "# - - - - - - - - END devctl - - - - - - - -\n"
+	tcs := map[string]string{
+		"unterminated": begin + "echo\n",
+		"duplicate":    begin + begin + end,
+		"unexpected":   end,
+	}
+	for name, content := range tcs {
+		t.Run(name, func(t *testing.T) {
+			fs := setup(t, content)
+			_, err := managed.NewEditor(fs).Apply(zshrc, "echo hi")
+			assert.Error(t, err)
+			assert.Equal(t, content, read(t, fs, zshrc))
+		})
+	}
+}
+
+func TestEditor_UserLinesMentioningMarkers(t *testing.T) {
+	content := `echo "BEGIN devctl"
+# END devctl notes
+  # - - - - - - - - BEGIN devctl - - - - - - - - and more
+`
+	fs := setup(t, content)
+	e := managed.NewEditor(fs)
+
+	_, found, err := e.Read(zshrc)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = e.Apply(zshrc, "echo hi")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(read(t, fs, zshrc), content+"\n"), "user lines are kept")
+	_, err = e.Apply(zshrc, "echo updated")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(read(t, fs, zshrc), content+"\n"), "user lines are kept")
+	assert.Contains(t, read(t, fs, zshrc), "echo updated\n")
+
+	_, err = e.Remove(zshrc)
+	require.NoError(t, err)
+	assert.Equal(t, content, read(t, fs, zshrc))
+}
+
+func TestEditor_IndentedMarkers(t *testing.T) {
+	content := userContent + "  # - - - - - - - - BEGIN devctl - - - - - - - -\necho old\n  # - - - - - - - - END devctl - - - - - - - -  \n"
+	fs := setup(t, content)
+
+	_, err := managed.NewEditor(fs).Remove(zshrc)
+	require.NoError(t, err)
+	assert.Equal(t, userContent, read(t, fs, zshrc))
+}
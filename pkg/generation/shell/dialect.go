@@ -0,0 +1,88 @@
+package shell
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Shell renders the sections of a Script in the syntax of a specific shell
+type Shell interface {
+	// Name returns the name of the shell, e.g. zsh
+	Name() string
+	// Quote quotes s literally, without any expansion
+	Quote(s string) string
+	// Value quotes value, keeping references to environment variables ($NAME, ${NAME})
+	Value(value string) string
+	Export(name, value string) string
+	PrependPath(dirs []string) string
+	Alias(name, command string) string
+	// Function defines the function name. body is written in the syntax of the shell.
+	Function(name, body string) []string
+	// Args translates the positional parameters ($1, $@, $#) of a POSIX function body
+	Args(body string) string
+	Completion(c Completion) []string
+	// Source sources file if it exists. Nushell is the exception: it resolves
+	// sourced files at parse time, so a missing file fails the whole script, and
+	// it only sources nu scripts.
+	Source(file string) string
+}
+
+var (
+	Bash       Shell = posix{name: "bash"}
+	Zsh        Shell = posix{name: "zsh"}
+	Fish       Shell = fish{}
+	PowerShell Shell = powershell{}
+	Nushell    Shell = nushell{}
+)
+
+// Shells returns all supported shells
+func Shells() []Shell {
+	return []Shell{Bash, Zsh, Fish, PowerShell, Nushell}
+}
+
+// ParseShell returns the Shell with the given name. pwsh and nu are accepted as aliases.
+func ParseShell(name string) (Shell, error) {
+	switch strings.ToLower(name) {
+	case "pwsh":
+		return PowerShell, nil
+	case "nu":
+		return Nushell, nil
+	}
+	for _, sh := range Shells() {
+		if sh.Name() == strings.ToLower(name) {
+			return sh, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported shell %q", name)
+}
+
+// completionScript substitutes the {shell} placeholder of the completion script
+func completionScript(sh Shell, c Completion) string {
+	return strings.ReplaceAll(c.Script, "{shell}", sh.Name())
+}
+
+var positionalRe = regexp.MustCompile(`\$(\{?)([0-9]|@|\*|#)(\}?)`)
+
+// translateArgs replaces the positional parameters of a POSIX body. n is called with
+// the 1-based index of the parameter, all for $@ / $* and count for $#.
+func translateArgs(body string, n func(i int) string, all, count string) string {
+	return positionalRe.ReplaceAllStringFunc(body, func(m string) string {
+		p := positionalRe.FindStringSubmatch(m)
+		if (p[1] == "{") != (p[3] == "}") {
+			return m
+		}
+		switch p[2] {
+		case "@", "*":
+			return all
+		case "#":
+			return count
+		case "0":
+			return m
+		default:
+			i, _ := strconv.Atoi(p[2])
+			return n(i)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fish renders scripts for the friendly interactive shell
+type fish struct{}
+
+func (fish) Name() string { return "fish" }
+
+func (fish) Quote(s string) string {
+	if s != "" && isSafe(s) {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// Value double quotes value. Variable references are closed off by ending the quoted
+// string, as fish does not support the ${NAME} syntax.
+func (fish) Value(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	sb := &strings.Builder{}
+	sb.WriteString(`"`)
+	segments := parseValue(value)
+	for i, s := range segments {
+		if !s.isVar {
+			sb.WriteString(r.Replace(s.text))
+			continue
+		}
+		sb.WriteString("$" + s.text)
+		if i+1 < len(segments) && !segments[i+1].isVar && isNameRune([]rune(segments[i+1].text)[0], false) {
+			sb.WriteString(`""`)
+		}
+	}
+	sb.WriteString(`"`)
+	return sb.String()
+}
+
+func (f fish) Export(name, value string) string {
+	return fmt.Sprintf("set -gx %s %s", name, f.Value(value))
+}
+
+func (f fish) PrependPath(dirs []string) string {
+	quoted := make([]string, 0, len(dirs)+1)
+	for _, d := range dirs {
+		quoted = append(quoted, f.Value(d))
+	}
+	return fmt.Sprintf("set -gx PATH %s $PATH", strings.Join(quoted, " "))
+}
+
+func (f fish) Alias(name, command string) string {
+	return fmt.Sprintf("alias %s %s", name, f.Quote(command))
+}
+
+func (fish) Function(name, body string) []string {
+	return block("function "+name, body, "end")
+}
+
+func (fish) Args(body string) string {
+	return translateArgs(body, func(i int) string { return fmt.Sprintf("$argv[%d]", i) }, "$argv", "(count $argv)")
+}
+
+func (f fish) Completion(c Completion) []string {
+	return []string{
+		fmt.Sprintf("if type -q %s", f.Quote(c.Command)),
+		indent(completionScript(f, c) + " | source"),
+		"end",
+	}
+}
+
+func (f fish) Source(file string) string {
+	q := f.Value(file)
+	return fmt.Sprintf("test -f %s; and source %s", q, q)
+}
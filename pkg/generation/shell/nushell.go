@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nushell renders scripts for nushell (nu)
+type nushell struct{}
+
+func (nushell) Name() string { return "nushell" }
+
+func (nushell) Quote(s string) string {
+	switch {
+	case s != "" && isSafe(s):
+		return s
+	case !strings.Contains(s, "'"):
+		return "'" + s + "'"
+	default:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+}
+
+// Value double quotes value. Values referencing variables become interpolated
+// strings, e.g. $"($env.HOME)/bin"
+func (nushell) Value(value string) string {
+	segments := parseValue(value)
+	interpolated := false
+	for _, s := range segments {
+		interpolated = interpolated || s.isVar
+	}
+	if !interpolated {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `(`, `\(`, `)`, `\)`)
+	sb := &strings.Builder{}
+	sb.WriteString(`$"`)
+	for _, s := range segments {
+		if s.isVar {
+			sb.WriteString("($env." + s.text + ")")
+			continue
+		}
+		sb.WriteString(r.Replace(s.text))
+	}
+	sb.WriteString(`"`)
+	return sb.String()
+}
+
+func (n nushell) Export(name, value string) string {
+	return fmt.Sprintf("$env.%s = %s", name, n.Value(value))
+}
+
+func (n nushell) PrependPath(dirs []string) string {
+	quoted := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		quoted = append(quoted, n.Value(d))
+	}
+	return fmt.Sprintf("$env.PATH = ($env.PATH | split row (char esep) | prepend [%s])", strings.Join(quoted, " "))
+}
+
+func (nushell) Alias(name, command string) string {
+	return fmt.Sprintf("alias %s = %s", name, command)
+}
+
+// Function defines a command with --env, so that changes of the environment, e.g. by
+// cd, are kept in the caller
+func (nushell) Function(name, body string) []string {
+	return block("def --env "+name+" [...args] {", body, "}")
+}
+
+var doubleQuotedRe = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// Args translates positional parameters. Double quoted strings referencing them
+// become interpolated strings, as nushell does not expand variables in plain strings.
+func (nushell) Args(body string) string {
+	index := func(i int) string { return fmt.Sprintf("$args.%d", i-1) }
+	out := &strings.Builder{}
+	last := 0
+	for _, loc := range doubleQuotedRe.FindAllStringIndex(body, -1) {
+		out.WriteString(translateArgs(body[last:loc[0]], index, "...$args", "($args | length)"))
+		quoted := body[loc[0]:loc[1]]
+		if positionalRe.MatchString(quoted) {
+			inner := strings.NewReplacer(`(`, `\(`, `)`, `\)`).Replace(quoted)
+			quoted = "$" + translateArgs(inner, func(i int) string { return "(" + index(i) + ")" }, "($args | str join ' ')", "($args | length)")
+		}
+		out.WriteString(quoted)
+		last = loc[1]
+	}
+	out.WriteString(translateArgs(body[last:], index, "...$args", "($args | length)"))
+	return out.String()
+}
+
+// Completion renders a comment, as nushell cannot source generated code at runtime
+func (n nushell) Completion(c Completion) []string {
+	return []string{
+		fmt.Sprintf("# %s: nushell cannot load completions at runtime.", c.Command),
+		fmt.Sprintf("# Save the output of `%s` to a file and source it instead.", completionScript(n, c)),
+	}
+}
+
+// Source sources nu scripts without an existence check. nushell reads sourced files
+// at parse time, so the file has to exist or the whole script fails to parse. There is
+// no runtime guard like in the other shells. Other files, e.g. ~/.fzf.zsh, would not
+// parse either and are skipped with a comment. $HOME is replaced by ~, as variables
+// are not available at parse time.
+func (n nushell) Source(file string) string {
+	if !strings.HasSuffix(file, ".nu") {
+		return fmt.Sprintf("# %s: nushell can only source nu scripts, skipped.", file)
+	}
+	for _, prefix := range []string{"$HOME", "${HOME}"} {
+		if strings.HasPrefix(file, prefix) {
+			file = "~" + strings.TrimPrefix(file, prefix)
+		}
+	}
+	return "source " + n.Quote(file)
+}
@@ -0,0 +1,57 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// posix renders POSIX compatible scripts for bash and zsh
+type posix struct {
+	name string
+}
+
+func (p posix) Name() string { return p.name }
+
+func (posix) Quote(s string) string { return Quote(s) }
+
+func (posix) Value(value string) string { return DoubleQuote(value) }
+
+func (p posix) Export(name, value string) string {
+	return fmt.Sprintf("export %s=%s", name, p.Value(value))
+}
+
+func (p posix) PrependPath(dirs []string) string {
+	return fmt.Sprintf("export PATH=%s", p.Value(strings.Join(append(append([]string{}, dirs...), "$PATH"), ":")))
+}
+
+func (p posix) Alias(name, command string) string {
+	return fmt.Sprintf("alias %s=%s", name, p.Quote(command))
+}
+
+func (posix) Function(name, body string) []string {
+	return block(name+"() {", body, "}")
+}
+
+func (posix) Args(body string) string { return body }
+
+func (p posix) Completion(c Completion) []string {
+	return []string{
+		fmt.Sprintf("if command -v %s >/dev/null 2>&1; then", p.Quote(c.Command)),
+		indent(fmt.Sprintf("source <(%s)", completionScript(p, c))),
+		"fi",
+	}
+}
+
+func (p posix) Source(file string) string {
+	q := p.Value(file)
+	return fmt.Sprintf("[ -f %s ] && source %s", q, q)
+}
+
+// block renders body indented between the open and close lines
+func block(open, body, close string) []string {
+	lines := []string{open}
+	for _, l := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		lines = append(lines, indent(l))
+	}
+	return append(lines, close)
+}
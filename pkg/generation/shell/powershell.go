@@ -0,0 +1,70 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// powershell renders scripts for PowerShell (pwsh)
+type powershell struct{}
+
+func (powershell) Name() string { return "powershell" }
+
+func (powershell) Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Value double quotes value, translating variable references to ${env:NAME}
+func (powershell) Value(value string) string {
+	r := strings.NewReplacer("`", "``", `"`, "`\"", `$`, "`$")
+	sb := &strings.Builder{}
+	sb.WriteString(`"`)
+	for _, s := range parseValue(value) {
+		if s.isVar {
+			sb.WriteString("${env:" + s.text + "}")
+			continue
+		}
+		sb.WriteString(r.Replace(s.text))
+	}
+	sb.WriteString(`"`)
+	return sb.String()
+}
+
+func (p powershell) Export(name, value string) string {
+	return fmt.Sprintf("$env:%s = %s", name, p.Value(value))
+}
+
+func (p powershell) PrependPath(dirs []string) string {
+	quoted := make([]string, 0, len(dirs)+1)
+	for _, d := range dirs {
+		quoted = append(quoted, p.Value(d))
+	}
+	return fmt.Sprintf("$env:PATH = @(%s, $env:PATH) -join [IO.Path]::PathSeparator", strings.Join(quoted, ", "))
+}
+
+// Alias defines a function, as PowerShell aliases cannot pass arguments
+func (powershell) Alias(name, command string) string {
+	return fmt.Sprintf("function %s { %s @args }", name, command)
+}
+
+func (powershell) Function(name, body string) []string {
+	return block("function "+name+" {", body, "}")
+}
+
+func (powershell) Args(body string) string {
+	// sub-expressions are required for indexing inside of double quoted strings
+	return translateArgs(body, func(i int) string { return fmt.Sprintf("$($args[%d])", i-1) }, "$args", "$($args.Count)")
+}
+
+func (p powershell) Completion(c Completion) []string {
+	return []string{
+		fmt.Sprintf("if (Get-Command %s -ErrorAction SilentlyContinue) {", p.Quote(c.Command)),
+		indent(completionScript(p, c) + " | Out-String | Invoke-Expression"),
+		"}",
+	}
+}
+
+func (p powershell) Source(file string) string {
+	q := p.Value(file)
+	return fmt.Sprintf("if (Test-Path %s) { . %s }", q, q)
+}
@@ -0,0 +1,37 @@
+package shell
+
+import (
+	"strings"
+)
+
+// Quote single quotes s for POSIX shells. The result is never expanded by the shell.
+func Quote(s string) string {
+	if s != "" && isSafe(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// DoubleQuote double quotes s for POSIX shells. Parameter expansion ($VAR) is kept,
+// while backslashes, double quotes and backticks are escaped.
+func DoubleQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`")
+	return `"` + r.Replace(s) + `"`
+}
+
+// isSafe returns true if s does not contain characters with a special meaning to the
+// shell. A leading = is expanded to the path of a command by zsh.
+func isSafe(s string) bool {
+	if strings.HasPrefix(s, "=") {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-_./:,+@%=", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+package shell
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/banner"
+)
+
+// SectionKind identifies a section of a generated init script. The value of a
+// SectionKind determines the position of the section in the script.
+type SectionKind int
+
+const (
+	SectionExports SectionKind = iota
+	SectionPath
+	SectionAliases
+	SectionFunctions
+	SectionCompletions
+	SectionSources
+)
+
+var sectionTitles = map[SectionKind]string{
+	SectionExports:     "Exports",
+	SectionPath:        "Path",
+	SectionAliases:     "Aliases",
+	SectionFunctions:   "Functions",
+	SectionCompletions: "Completions",
+	SectionSources:     "Sources",
+}
+
+// Title returns the banner title of the section
+func (k SectionKind) Title() string { return sectionTitles[k] }
+
+// Function is a shell function. Body contains the POSIX commands of the function,
+// one per line. Positional parameters of Body are translated for other shells, while
+// Bodies holds native bodies keyed by the name of a Shell.
+type Function struct {
+	Name   string
+	Body   string
+	Bodies map[string]string
+}
+
+// body returns the body of the function for sh
+func (f Function) body(sh Shell) string {
+	if b, ok := f.Bodies[sh.Name()]; ok {
+		return b
+	}
+	return sh.Args(f.Body)
+}
+
+// Completion loads the completions of Command by sourcing the output of Script.
+// The completion is only loaded if Command is found on the PATH. The placeholder
+// {shell} in Script is replaced by the name of the rendered shell.
+type Completion struct {
+	Command string
+	Script  string
+}
+
+// Script describes a shell init script assembled from typed sections, independent of
+// the Shell it is rendered for.
+//
+// Sections are rendered in the order of their SectionKind, each headed by a banner.
+// Exports, aliases, functions and completions are sorted by name; PATH entries and
+// sourced files keep the order they were added in, as their order is significant.
+type Script struct {
+	exports     map[string]string
+	path        []string
+	aliases     map[string]string
+	functions   map[string]Function
+	completions map[string]Completion
+	sources     []string
+}
+
+// NewScript returns an empty Script
+func NewScript() *Script {
+	return &Script{
+		exports:     map[string]string{},
+		aliases:     map[string]string{},
+		functions:   map[string]Function{},
+		completions: map[string]Completion{},
+	}
+}
+
+// Export sets the environment variable name to value. Values are double quoted,
+// so they may reference other variables.
+func (s *Script) Export(name, value string) *Script {
+	s.exports[name] = value
+	return s
+}
+
+// PrependPath adds dir to the front of $PATH. Duplicate entries are ignored.
+func (s *Script) PrependPath(dir string) *Script {
+	for _, p := range s.path {
+		if p == dir {
+			return s
+		}
+	}
+	s.path = append(s.path, dir)
+	return s
+}
+
+// Alias defines the alias name for command
+func (s *Script) Alias(name, command string) *Script {
+	s.aliases[name] = command
+	return s
+}
+
+// Function defines the shell function name using a POSIX body
+func (s *Script) Function(name, body string) *Script {
+	f := s.functions[name]
+	f.Name, f.Body = name, body
+	s.functions[name] = f
+	return s
+}
+
+// ShellFunction defines the body of the function name for sh, overriding the
+// translated POSIX body
+func (s *Script) ShellFunction(sh Shell, name, body string) *Script {
+	f := s.functions[name]
+	f.Name = name
+	if f.Bodies == nil {
+		f.Bodies = map[string]string{}
+	}
+	f.Bodies[sh.Name()] = body
+	s.functions[name] = f
+	return s
+}
+
+// Completion loads the completions of command from the output of script
+func (s *Script) Completion(command, script string) *Script {
+	s.completions[command] = Completion{Command: command, Script: script}
+	return s
+}
+
+// Source sources file if it exists. For Nushell the file has to exist and be a nu
+// script, see Shell.Source.
+func (s *Script) Source(file string) *Script {
+	for _, src := range s.sources {
+		if src == file {
+			return s
+		}
+	}
+	s.sources = append(s.sources, file)
+	return s
+}
+
+// String renders the script for Zsh
+func (s *Script) String() string {
+	return s.Render(Zsh)
+}
+
+// Render renders the script for sh
+func (s *Script) Render(sh Shell) string {
+	sb := &strings.Builder{}
+	_, _ = s.WriteShell(sb, sh)
+	return sb.String()
+}
+
+// WriteTo renders the script for Zsh into w
+func (s *Script) WriteTo(w io.Writer) (n int64, err error) {
+	return s.WriteShell(w, Zsh)
+}
+
+// WriteShell renders the script for sh into w
+func (s *Script) WriteShell(w io.Writer, sh Shell) (n int64, err error) {
+	first := true
+	for _, kind := range []SectionKind{SectionExports, SectionPath, SectionAliases, SectionFunctions, SectionCompletions, SectionSources} {
+		lines := s.section(sh, kind)
+		if len(lines) == 0 {
+			continue
+		}
+		sb := &strings.Builder{}
+		if !first {
+			sb.WriteString("\n")
+		}
+		first = false
+		sb.WriteString(banner.GenerateBanner(kind.Title(), banner.KIND_SHELL))
+		sb.WriteString("\n")
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+
+		written, err := io.WriteString(w, sb.String())
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// section returns the lines of the section kind rendered for sh
+func (s *Script) section(sh Shell, kind SectionKind) (lines []string) {
+	switch kind {
+	case SectionExports:
+		for _, name := range sortedKeys(s.exports) {
+			lines = append(lines, sh.Export(name, s.exports[name]))
+		}
+	case SectionPath:
+		if len(s.path) > 0 {
+			lines = append(lines, sh.PrependPath(s.path))
+		}
+	case SectionAliases:
+		for _, name := range sortedKeys(s.aliases) {
+			lines = append(lines, sh.Alias(name, s.aliases[name]))
+		}
+	case SectionFunctions:
+		names := make([]string, 0, len(s.functions))
+		for name := range s.functions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, sh.Function(name, s.functions[name].body(sh))...)
+		}
+	case SectionCompletions:
+		commands := make([]string, 0, len(s.completions))
+		for cmd := range s.completions {
+			commands = append(commands, cmd)
+		}
+		sort.Strings(commands)
+		for _, cmd := range commands {
+			lines = append(lines, sh.Completion(s.completions[cmd])...)
+		}
+	case SectionSources:
+		for _, file := range s.sources {
+			lines = append(lines, sh.Source(file))
+		}
+	}
+	return lines
+}
+
+func indent(line string) string {
+	if line == "" {
+		return line
+	}
+	return "  " + line
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
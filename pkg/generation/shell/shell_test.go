@@ -0,0 +1,134 @@
+package shell_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alex-held/gold"
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alex-held/devctl-kit/pkg/generation/shell"
+)
+
+func newScript() *shell.Script {
+	return shell.NewScript().
+		Export("EDITOR", "nvim").
+		Export("DEVCTL_ROOT", "$HOME/.devctl").
+		Export("GREETING", `say "hi"`).
+		PrependPath("$DEVCTL_ROOT/bin").
+		PrependPath("$HOME/go/bin").
+		PrependPath("$DEVCTL_ROOT/bin").
+		Alias("ll", "ls -la").
+		Alias("k", "kubectl").
+		Alias("gs", "git status").
+		Function("mkcd", "mkdir -p \"$1\"\ncd \"$1\"").
+		Function("reload", "source ~/.zshrc").
+		ShellFunction(shell.Fish, "reload", "source ~/.config/fish/config.fish").
+		Completion("kubectl", "kubectl completion {shell}").
+		Completion("devctl", "devctl completion {shell}").
+		Source("$HOME/.fzf.zsh").
+		Source("$HOME/.zshrc.local")
+}
+
+func TestScript(t *testing.T) {
+	g := gold.New(t, goldie.WithTestNameForDir(true), goldie.WithDiffEngine(goldie.ColoredDiff))
+	g.Assert(t, "init", []byte(newScript().String()))
+}
+
+func TestScript_Shells(t *testing.T) {
+	for _, sh := range shell.Shells() {
+		t.Run(sh.Name(), func(t *testing.T) {
+			g := gold.New(t, goldie.WithTestNameForDir(true), goldie.WithDiffEngine(goldie.ColoredDiff))
+			g.Assert(t, sh.Name(), []byte(newScript().Render(sh)))
+		})
+	}
+}
+
+func TestShell_Value(t *testing.T) {
+	tcs := []struct {
+		shell    shell.Shell
+		value    string
+		expected string
+	}{
+		{shell.Bash, `$HOME/"bin"`, `"$HOME/\"bin\""`},
+		{shell.Fish, `${HOME}/bin`, `"$HOME/bin"`},
+		{shell.Fish, `${GOPATH}_x $5`, `"$GOPATH""_x \$5"`},
+		{shell.PowerShell, `$HOME/bin`, `"${env:HOME}/bin"`},
+		{shell.PowerShell, "cost: $5 `x`", "\"cost: `$5 ``x``\""},
+		{shell.Nushell, `$HOME/bin (x)`, `$"($env.HOME)/bin \(x\)"`},
+		{shell.Nushell, `plain "text"`, `"plain \"text\""`},
+		{shell.Fish, "${" + strings.Repeat("ä", 64) + "}", `"\${` + strings.Repeat("ä", 64) + `}"`},
+		{shell.Fish, `${ä}/$HOME/bin`, `"\${ä}/$HOME/bin"`},
+		{shell.PowerShell, `$ä/${GOPATH}ö`, "\"`$ä/${env:GOPATH}ö\""},
+		{shell.Nushell, `ü${HOME}ü`, `$"ü($env.HOME)ü"`},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.shell.Name()+"/"+tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.shell.Value(tt.value))
+		})
+	}
+}
+
+func TestShell_Source(t *testing.T) {
+	assert.Equal(t, `[ -f "$HOME/.env" ] && source "$HOME/.env"`, shell.Bash.Source("$HOME/.env"))
+	assert.Equal(t, `test -f "$HOME/.env"; and source "$HOME/.env"`, shell.Fish.Source("$HOME/.env"))
+	assert.Equal(t, `if (Test-Path "${env:HOME}/.env") { . "${env:HOME}/.env" }`, shell.PowerShell.Source("$HOME/.env"))
+	// nushell sources nu scripts at parse time, the file must exist
+	assert.Equal(t, "source '~/my env.nu'", shell.Nushell.Source("${HOME}/my env.nu"))
+	assert.Equal(t, "# $HOME/.env: nushell can only source nu scripts, skipped.", shell.Nushell.Source("$HOME/.env"))
+}
+
+func TestShell_Args(t *testing.T) {
+	body := `echo "$1" "${2}" $# $@`
+	assert.Equal(t, body, shell.Zsh.Args(body))
+	assert.Equal(t, `echo "$argv[1]" "$argv[2]" (count $argv) $argv`, shell.Fish.Args(body))
+	assert.Equal(t, `echo "$($args[0])" "$($args[1])" $($args.Count) $args`, shell.PowerShell.Args(body))
+	assert.Equal(t, `echo $"($args.0)" $"($args.1)" ($args | length) ...$args`, shell.Nushell.Args(body))
+	assert.Equal(t, `cd $args.0; echo "(x)"`, shell.Nushell.Args(`cd $1; echo "(x)"`))
+}
+
+func TestParseShell(t *testing.T) {
+	for name, expected := range map[string]shell.Shell{
+		"bash": shell.Bash, "ZSH": shell.Zsh, "fish": shell.Fish,
+		"pwsh": shell.PowerShell, "powershell": shell.PowerShell, "nu": shell.Nushell,
+	} {
+		actual, err := shell.ParseShell(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	_, err := shell.ParseShell("tcsh")
+	assert.Error(t, err)
+}
+
+func TestScript_Deterministic(t *testing.T) {
+	expected := newScript().String()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, expected, newScript().String())
+	}
+}
+
+func TestScript_SkipsEmptySections(t *testing.T) {
+	actual := shell.NewScript().Alias("k", "kubectl").String()
+	assert.Contains(t, actual, "alias k=kubectl\n")
+	assert.NotContains(t, actual, "export")
+}
+
+func TestQuote(t *testing.T) {
+	tcs := map[string]string{
+		"kubectl":     "kubectl",
+		"ls -la":      "'ls -la'",
+		"it's":        `'it'\''s'`,
+		"":            "''",
+		"$HOME/.bin":  "'$HOME/.bin'",
+		"a=b,c:d@e+f": "a=b,c:d@e+f",
+		"=ls":         "'=ls'",
+	}
+	for in, expected := range tcs {
+		assert.Equal(t, expected, shell.Quote(in), in)
+	}
+}
+
+func TestDoubleQuote(t *testing.T) {
+	assert.Equal(t, `"$HOME/\"x\"\\\`+"`"+`"`, shell.DoubleQuote(`$HOME/"x"\`+"`"))
+}
@@ -0,0 +1,78 @@
+package shell
+
+import "strings"
+
+// segment is either a literal part of a value or a reference to an environment variable
+type segment struct {
+	text  string
+	isVar bool
+}
+
+// parseValue splits value into literals and $NAME / ${NAME} variable references
+func parseValue(value string) (segments []segment) {
+	literal := &strings.Builder{}
+	flush := func() {
+		if literal.Len() > 0 {
+			segments = append(segments, segment{text: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	r := []rune(value)
+	for i := 0; i < len(r); i++ {
+		if r[i] != '$' || i+1 >= len(r) {
+			literal.WriteRune(r[i])
+			continue
+		}
+		if r[i+1] == '{' {
+			end := -1
+			for k, c := range r[i+2:] {
+				if c == '}' {
+					end = k
+					break
+				}
+			}
+			if end > 0 && isName(string(r[i+2:i+2+end])) {
+				flush()
+				segments = append(segments, segment{text: string(r[i+2 : i+2+end]), isVar: true})
+				i += 2 + end
+				continue
+			}
+			literal.WriteRune(r[i])
+			continue
+		}
+		j := i + 1
+		for j < len(r) && isNameRune(r[j], j == i+1) {
+			j++
+		}
+		if j == i+1 {
+			literal.WriteRune(r[i])
+			continue
+		}
+		flush()
+		segments = append(segments, segment{text: string(r[i+1 : j]), isVar: true})
+		i = j - 1
+	}
+	flush()
+	return segments
+}
+
+func isName(s string) bool {
+	for i, c := range s {
+		if !isNameRune(c, i == 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isNameRune(c rune, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	default:
+		return false
+	}
+}
@@ -0,0 +1,53 @@
+package index
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Git clones and updates index repositories
+type Git interface {
+	// Clone clones the repository at uri into dir
+	Clone(uri, dir string) error
+	// Update fetches the default branch of the repository at uri and resets the
+	// clone in dir to it, discarding local changes
+	Update(uri, dir string) error
+	// Head returns the commit checked out in dir
+	Head(dir string) (string, error)
+}
+
+// ExecGit is a Git running the git binary of the PATH.
+// It works on the os file system only.
+type ExecGit struct{}
+
+func (ExecGit) Clone(uri, dir string) error {
+	_, err := run("clone", "--quiet", "--", uri, dir)
+	return err
+}
+
+func (ExecGit) Update(uri, dir string) error {
+	if _, err := run("-C", dir, "fetch", "--quiet", "--", uri, "HEAD"); err != nil {
+		return err
+	}
+	_, err := run("-C", dir, "reset", "--hard", "--quiet", "FETCH_HEAD")
+	return err
+}
+
+func (ExecGit) Head(dir string) (string, error) {
+	return run("-C", dir, "rev-parse", "HEAD")
+}
+
+func run(args ...string) (string, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
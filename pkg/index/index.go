@@ -0,0 +1,307 @@
+// Package index manages the git repositories containing plugin manifests.
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/config"
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+// ErrNotFound is returned if an index has not been added
+var ErrNotFound = errors.New("index not found")
+
+// ErrExists is returned when adding an index whose name is taken
+var ErrExists = errors.New("index already exists")
+
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName returns an error if name can not be used as an index name
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return errors.Errorf("invalid index name %q, expected letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// Index is a plugin index and the metadata recorded by its last update
+type Index struct {
+	Name      string    `yaml:"name"`
+	URI       string    `yaml:"uri"`
+	Commit    string    `yaml:"commit"`
+	AddedAt   time.Time `yaml:"addedAt"`
+	UpdatedAt time.Time `yaml:"updatedAt"`
+
+	// Path is the directory the index is cloned to
+	Path string `yaml:"-"`
+}
+
+// Manager adds, updates and removes the indexes cloned to Paths.IndexBase. The
+// metadata of an index is kept in Paths.State, outside of its repository.
+type Manager struct {
+	fs     afero.Fs
+	paths  env.Paths
+	config func() (*config.Config, error)
+	git    Git
+	now    func() time.Time
+}
+
+type ManagerOption func(*Manager) *Manager
+
+// WithGit sets the Git the repositories are cloned and updated with
+func WithGit(git Git) ManagerOption {
+	return func(m *Manager) *Manager {
+		m.git = git
+		return m
+	}
+}
+
+// WithClock sets the function the update times are taken from
+func WithClock(now func() time.Time) ManagerOption {
+	return func(m *Manager) *Manager {
+		m.now = now
+		return m
+	}
+}
+
+// NewManager returns a Manager using the Fs, Paths and Config of f.
+// By default, repositories are cloned with ExecGit, which requires f to use the os file system.
+func NewManager(f env.Factory, opts ...ManagerOption) *Manager {
+	m := &Manager{
+		fs:     f.Fs(),
+		paths:  f.Paths(),
+		config: f.Config,
+		git:    ExecGit{},
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// metadataPath returns the file the metadata of the index name is recorded in
+func (m *Manager) metadataPath(name string) string {
+	return m.paths.State(constants.IndexDir, name+constants.ManifestExtension)
+}
+
+// Get returns the index name. Indexes cloned without a Manager have no metadata
+// besides their name and path.
+func (m *Manager) Get(name string) (*Index, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	path := m.paths.IndexPath(name)
+	if exists, err := afero.DirExists(m.fs, path); err != nil {
+		return nil, errors.Wrapf(err, "failed to stat %s", path)
+	} else if !exists {
+		return nil, errors.Wrapf(ErrNotFound, "index %s", name)
+	}
+
+	idx := &Index{Name: name}
+	b, err := afero.ReadFile(m.fs, m.metadataPath(name))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read the metadata of index %s", name)
+	} else if err == nil {
+		if err = yaml.Unmarshal(b, idx); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", m.metadataPath(name))
+		}
+	}
+	idx.Name, idx.Path = name, path
+	return idx, nil
+}
+
+// List returns the indexes sorted by name
+func (m *Manager) List() ([]*Index, error) {
+	infos, err := afero.ReadDir(m.fs, m.paths.IndexBase())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", m.paths.IndexBase())
+	}
+	var indexes []*Index
+	for _, fi := range infos {
+		if !fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		idx, err := m.Get(fi.Name())
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	return indexes, nil
+}
+
+// Add clones the repository at uri as the index name. The clone is moved into place
+// once it is complete, so a failed clone leaves no index behind.
+func (m *Manager) Add(name, uri string) (*Index, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if uri == "" {
+		return nil, errors.Errorf("no uri given for index %s", name)
+	}
+	path := m.paths.IndexPath(name)
+	if exists, err := afero.Exists(m.fs, path); err != nil {
+		return nil, errors.Wrapf(err, "failed to stat %s", path)
+	} else if exists {
+		return nil, errors.Wrapf(ErrExists, "index %s", name)
+	}
+
+	if err := m.fs.MkdirAll(m.paths.IndexBase(), 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", m.paths.IndexBase())
+	}
+	tmp, err := afero.TempDir(m.fs, m.paths.IndexBase(), "."+name+"-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer m.fs.RemoveAll(tmp)
+
+	staged := filepath.Join(tmp, name)
+	if err = m.git.Clone(uri, staged); err != nil {
+		return nil, errors.Wrapf(err, "failed to clone index %s from %s", name, uri)
+	}
+	commit, err := m.git.Head(staged)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.fs.Rename(staged, path); err != nil {
+		return nil, errors.Wrapf(err, "failed to move index %s into place", name)
+	}
+
+	now := m.now().UTC().Truncate(time.Second)
+	idx := &Index{Name: name, URI: uri, Commit: commit, AddedAt: now, UpdatedAt: now, Path: path}
+	if err = m.writeMetadata(idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Update fetches the latest commit of the indexes with the given names, or of all
+// indexes if no names are given. The default index is added if it is missing and no
+// names are given.
+func (m *Manager) Update(names ...string) ([]*Index, error) {
+	var updated []*Index
+	if len(names) == 0 {
+		c, err := m.config()
+		if err != nil {
+			return nil, err
+		}
+		indexes, err := m.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range indexes {
+			names = append(names, idx.Name)
+		}
+		if !contains(names, c.Index.Name) {
+			idx, err := m.Add(c.Index.Name, c.Index.URI)
+			if err != nil {
+				return nil, err
+			}
+			updated = append(updated, idx)
+		}
+	}
+
+	for _, name := range names {
+		idx, err := m.update(name)
+		if err != nil {
+			return updated, err
+		}
+		updated = append(updated, idx)
+	}
+	sort.Slice(updated, func(i, j int) bool { return updated[i].Name < updated[j].Name })
+	return updated, nil
+}
+
+// update fetches the index name from its recorded uri. The default index is always
+// fetched from the configured uri, so that overriding it takes effect on the next update.
+func (m *Manager) update(name string) (*Index, error) {
+	idx, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	c, err := m.config()
+	if err != nil {
+		return nil, err
+	}
+	if name == c.Index.Name {
+		idx.URI = c.Index.URI
+	} else if idx.URI == "" {
+		return nil, errors.Errorf("the uri of index %s is unknown, remove and add it again", name)
+	}
+
+	if err = m.git.Update(idx.URI, idx.Path); err != nil {
+		return nil, errors.Wrapf(err, "failed to update index %s from %s", name, idx.URI)
+	}
+	if idx.Commit, err = m.git.Head(idx.Path); err != nil {
+		return nil, err
+	}
+	idx.UpdatedAt = m.now().UTC().Truncate(time.Second)
+	if idx.AddedAt.IsZero() {
+		idx.AddedAt = idx.UpdatedAt
+	}
+	return idx, m.writeMetadata(idx)
+}
+
+// EnsureDefault adds the default index of the configuration if it is missing. Its
+// name and uri are the index.name and index.uri keys, the latter can be overridden by
+// constants.DEVCTL_DEFAULT_INDEX_URI_KEY.
+func (m *Manager) EnsureDefault() (*Index, error) {
+	c, err := m.config()
+	if err != nil {
+		return nil, err
+	}
+	idx, err := m.Get(c.Index.Name)
+	if errors.Is(err, ErrNotFound) {
+		return m.Add(c.Index.Name, c.Index.URI)
+	}
+	return idx, err
+}
+
+// Remove deletes the clone and the metadata of the index name
+func (m *Manager) Remove(name string) error {
+	if _, err := m.Get(name); err != nil {
+		return err
+	}
+	if err := m.fs.RemoveAll(m.paths.IndexPath(name)); err != nil {
+		return errors.Wrapf(err, "failed to remove index %s", name)
+	}
+	if err := m.fs.Remove(m.metadataPath(name)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove the metadata of index %s", name)
+	}
+	return nil
+}
+
+func (m *Manager) writeMetadata(idx *Index) error {
+	b, err := yaml.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	path := m.metadataPath(idx.Name)
+	if err = m.fs.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrapf(err, "failed to create %s", filepath.Dir(path))
+	}
+	return env.WriteFileAtomic(m.fs, path, b, 0644)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
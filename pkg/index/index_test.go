@@ -0,0 +1,178 @@
+package index_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/index"
+)
+
+var clock = func() time.Time { return time.Date(2021, 10, 1, 12, 0, 0, 0, time.Local) }
+
+// repo is a local git repository served to the Manager by a file:// uri
+type repo struct {
+	t   *testing.T
+	dir string
+}
+
+func newRepo(t *testing.T) *repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	r := &repo{t: t, dir: t.TempDir()}
+	r.git("init", "--quiet")
+	r.commit("plugins/go.yaml", "name: go\n")
+	return r
+}
+
+func (r *repo) uri() string { return "file://" + filepath.ToSlash(r.dir) }
+
+func (r *repo) git(args ...string) string {
+	r.t.Helper()
+	args = append([]string{"-C", r.dir, "-c", "user.name=devctl", "-c", "user.email=devctl@example.com"}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(r.t, err, string(out))
+	return string(out)
+}
+
+// commit writes content to the file path of the repository and commits it
+func (r *repo) commit(path, content string) string {
+	r.t.Helper()
+	fs := afero.NewOsFs()
+	require.NoError(r.t, fs.MkdirAll(filepath.Join(r.dir, filepath.Dir(path)), 0755))
+	require.NoError(r.t, afero.WriteFile(fs, filepath.Join(r.dir, path), []byte(content), 0644))
+	r.git("add", "--all")
+	r.git("commit", "--quiet", "--message", "update "+path)
+	out := r.git("rev-parse", "HEAD")
+	return out[:len(out)-1]
+}
+
+func newManager(t *testing.T) (*index.Manager, *envtest.Env) {
+	e := envtest.New(t, env.WithFs(afero.NewOsFs()))
+	return index.NewManager(e.Factory, index.WithClock(clock)), e
+}
+
+func TestManager_Add(t *testing.T) {
+	r := newRepo(t)
+	m, e := newManager(t)
+
+	idx, err := m.Add("company", r.uri())
+	require.NoError(t, err)
+	assert.Equal(t, "company", idx.Name)
+	assert.Equal(t, r.uri(), idx.URI)
+	assert.Equal(t, e.Paths.IndexPath("company"), idx.Path)
+	assert.Equal(t, r.git("rev-parse", "HEAD")[:40], idx.Commit)
+	assert.Equal(t, clock().UTC(), idx.UpdatedAt)
+
+	exists, err := afero.Exists(e.Fs, e.Paths.IndexPluginManifestPath("company", "go"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	got, err := m.Get("company")
+	require.NoError(t, err)
+	assert.Equal(t, idx, got)
+
+	infos, err := afero.ReadDir(e.Fs, e.Paths.IndexBase())
+	require.NoError(t, err)
+	assert.Len(t, infos, 1, "the staging directory is removed")
+
+	_, err = m.Add("company", r.uri())
+	assert.True(t, errors.Is(err, index.ErrExists))
+}
+
+func TestManager_Add_Errors(t *testing.T) {
+	m, e := newManager(t)
+
+	_, err := m.Add("../escape", "file:///tmp")
+	assert.EqualError(t, err, `invalid index name "../escape", expected letters, digits, '.', '_' or '-'`)
+
+	_, err = m.Add("missing", "file://"+filepath.ToSlash(filepath.Join(e.WorkingDir, "missing")))
+	assert.Error(t, err)
+	indexes, err := m.List()
+	require.NoError(t, err)
+	assert.Empty(t, indexes, "a failed clone leaves no index behind")
+}
+
+func TestManager_Update(t *testing.T) {
+	r := newRepo(t)
+	m, _ := newManager(t)
+	_, err := m.Add("company", r.uri())
+	require.NoError(t, err)
+
+	commit := r.commit("plugins/node.yaml", "name: node\n")
+	updated, err := m.Update("company")
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Equal(t, commit, updated[0].Commit)
+
+	got, err := m.Get("company")
+	require.NoError(t, err)
+	assert.Equal(t, commit, got.Commit)
+
+	_, err = m.Update("missing")
+	assert.True(t, errors.Is(err, index.ErrNotFound))
+}
+
+func TestManager_Update_AddsDefault(t *testing.T) {
+	def, other := newRepo(t), newRepo(t)
+	m, e := newManager(t)
+	e.Environ[constants.DEVCTL_DEFAULT_INDEX_URI_KEY] = def.uri()
+	_, err := m.Add("other", other.uri())
+	require.NoError(t, err)
+
+	updated, err := m.Update()
+	require.NoError(t, err)
+	require.Len(t, updated, 2)
+	assert.Equal(t, constants.DefaultIndexName, updated[0].Name)
+	assert.Equal(t, def.uri(), updated[0].URI)
+	assert.Equal(t, "other", updated[1].Name)
+}
+
+func TestManager_Update_DefaultURIOverride(t *testing.T) {
+	old, overridden := newRepo(t), newRepo(t)
+	m, e := newManager(t)
+	_, err := m.Add(constants.DefaultIndexName, old.uri())
+	require.NoError(t, err)
+
+	e.Environ[constants.DEVCTL_DEFAULT_INDEX_URI_KEY] = overridden.uri()
+	commit := overridden.commit("plugins/node.yaml", "name: node\n")
+	updated, err := m.Update(constants.DefaultIndexName)
+	require.NoError(t, err)
+	assert.Equal(t, overridden.uri(), updated[0].URI)
+	assert.Equal(t, commit, updated[0].Commit)
+}
+
+func TestManager_ListRemove(t *testing.T) {
+	r := newRepo(t)
+	m, e := newManager(t)
+	for _, name := range []string{"b", "a"} {
+		_, err := m.Add(name, r.uri())
+		require.NoError(t, err)
+	}
+	indexes, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, indexes, 2)
+	assert.Equal(t, "a", indexes[0].Name)
+	assert.Equal(t, "b", indexes[1].Name)
+
+	require.NoError(t, m.Remove("a"))
+	exists, err := afero.Exists(e.Fs, e.Paths.IndexPath("a"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = afero.Exists(e.Fs, e.Paths.State(constants.IndexDir, "a.yaml"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.True(t, errors.Is(m.Remove("a"), index.ErrNotFound))
+}
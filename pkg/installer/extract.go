@@ -0,0 +1,229 @@
+package installer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+const osCreate = os.O_CREATE | os.O_EXCL | os.O_WRONLY
+
+// extract unpacks the artifact downloaded from uri at src into dir. The format is
+// inferred from the extension of the uri, artifacts which are no .tar.gz, .tgz, .tar
+// or .zip archive are executables copied to dir.
+func (i *Installer) extract(uri, src, dir string) error {
+	if err := i.fs.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := strings.ToLower(path.Base(uri))
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return i.extractTar(src, dir, true)
+	case strings.HasSuffix(name, ".tar"):
+		return i.extractTar(src, dir, false)
+	case strings.HasSuffix(name, ".zip"):
+		return i.extractZip(src, dir)
+	}
+	f, err := i.fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return i.writeFile(filepath.Join(dir, path.Base(uri)), f, 0755)
+}
+
+func (i *Installer) extractTar(src, dir string, gzipped bool) error {
+	f, err := i.fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return errors.Wrap(err, "failed to read gzip archive")
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to read tar archive")
+		}
+		target, err := i.join(dir, h.Name)
+		if err != nil {
+			return err
+		}
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = i.fs.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = i.writeFile(target, tr, os.FileMode(h.Mode).Perm())
+		case tar.TypeSymlink:
+			err = i.symlink(dir, h.Linkname, target)
+		case tar.TypeXGlobalHeader:
+		default:
+			err = errors.Errorf("unsupported type %q of entry %s", h.Typeflag, h.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (i *Installer) extractZip(src, dir string) error {
+	f, err := i.fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(f, fi.Size())
+	if err != nil {
+		return errors.Wrap(err, "failed to read zip archive")
+	}
+
+	for _, zf := range zr.File {
+		target, err := i.join(dir, zf.Name)
+		if err != nil {
+			return err
+		}
+		mode := zf.Mode()
+		if mode.IsDir() {
+			if err = i.fs.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read entry %s", zf.Name)
+		}
+		if mode&os.ModeSymlink != 0 {
+			var link []byte
+			if link, err = ioutil.ReadAll(r); err == nil {
+				err = i.symlink(dir, string(link), target)
+			}
+		} else {
+			err = i.writeFile(target, r, mode.Perm())
+		}
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Installer) writeFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := i.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := i.fs.OpenFile(path, osCreate, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to extract %s", path)
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	// the permissions of OpenFile are subject to the umask
+	return i.fs.Chmod(path, perm)
+}
+
+// symlink creates the link of an archive entry, which must not point outside of dir
+func (i *Installer) symlink(dir, oldname, newname string) error {
+	if filepath.IsAbs(oldname) {
+		return errors.Errorf("link %s has an absolute target %s", newname, oldname)
+	}
+	realDir, err := i.realpath(dir)
+	if err != nil {
+		return err
+	}
+	parent, err := i.realpath(filepath.Dir(newname))
+	if err != nil {
+		return err
+	}
+	// the target is resolved unclean, as ".." follows the links before it
+	target, err := i.realpath(parent + string(filepath.Separator) + filepath.FromSlash(oldname))
+	if err != nil || !within(realDir, target) {
+		return errors.Errorf("link %s points outside of the archive", newname)
+	}
+	if err = i.fs.MkdirAll(filepath.Dir(newname), 0755); err != nil {
+		return err
+	}
+	return env.Symlink(i.fs, filepath.FromSlash(oldname), newname)
+}
+
+// join returns name below dir or an error if the archive entry escapes dir, either by
+// its name or through the links of earlier entries
+func (i *Installer) join(dir, name string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if !within(dir, path) {
+		return "", errors.Errorf("archive entry %s is outside of the archive", name)
+	}
+	realDir, err := i.realpath(dir)
+	if err != nil {
+		return "", err
+	}
+	if real, err := i.realpath(path); err != nil || !within(realDir, real) {
+		return "", errors.Errorf("archive entry %s is outside of the archive", name)
+	}
+	return path, nil
+}
+
+// realpath evaluates the symbolic links of path like env.EvalSymlinks, but takes
+// elements which do not exist yet as directories. A ".." after such an element is
+// refused, as its meaning changes if a later entry creates a link there.
+func (i *Installer) realpath(path string) (string, error) {
+	real, err := env.EvalSymlinks(i.fs, path)
+	if err == nil || !os.IsNotExist(err) {
+		return real, err
+	}
+	sep := strings.LastIndexByte(path, filepath.Separator)
+	if sep < 0 {
+		return "", err
+	}
+	parent, elem := path[:sep], path[sep+1:]
+	if parent == "" {
+		parent = string(filepath.Separator)
+	}
+	if real, err = i.realpath(parent); err != nil {
+		return "", err
+	}
+	switch elem {
+	case "", ".":
+		return real, nil
+	case "..":
+		return "", errors.Errorf("%s can not be resolved before %s exists", path, parent)
+	}
+	return filepath.Join(real, elem), nil
+}
+
+// within returns whether the clean path is dir or below it
+func within(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
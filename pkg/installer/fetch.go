@@ -0,0 +1,64 @@
+package installer
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/alex-held/devctl-kit/pkg/blob"
+)
+
+// fetch makes sure the artifact at uri is stored as the blob sum. It is downloaded
+// unless the blob exists and is intact, e.g. because another version or profile uses
+// the same artifact. http and https uris are downloaded with the http.Client of the
+// Installer, file uris are read from its afero.Fs.
+func (i *Installer) fetch(uri, sum string) error {
+	if ok, err := i.blobs.Check(sum); err != nil {
+		return err
+	} else if ok {
+		i.log.Debugf("using the stored artifact %s", uri)
+		return nil
+	}
+
+	r, err := i.open(uri)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	i.log.Debugf("downloading %s", uri)
+	if err = i.blobs.Put(sum, r); errors.Is(err, blob.ErrChecksumMismatch) {
+		return errors.Wrapf(err, "artifact %s", uri)
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to download %s", uri)
+	}
+	return nil
+}
+
+func (i *Installer) open(uri string) (io.ReadCloser, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid uri %q", uri)
+	}
+	switch u.Scheme {
+	case "file":
+		f, err := i.fs.Open(filepath.FromSlash(u.Path))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open %s", uri)
+		}
+		return f, nil
+	case "http", "https":
+		resp, err := i.client.Get(uri)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download %s", uri)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, errors.Errorf("failed to download %s: %s", uri, resp.Status)
+		}
+		return resp.Body, nil
+	}
+	return nil, errors.Errorf("unsupported scheme of uri %q", uri)
+}
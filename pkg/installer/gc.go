@@ -0,0 +1,209 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/blob"
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+)
+
+// GarbageKind describes why GC considers a path garbage
+type GarbageKind string
+
+const (
+	// GarbageVersion is an install directory of a version without receipt, which is
+	// not the active version
+	GarbageVersion GarbageKind = "version"
+	// GarbageStaging is a staging directory of an interrupted install or uninstall
+	GarbageStaging GarbageKind = "staging"
+	// GarbageTemp is a temporary file or link of an interrupted write
+	GarbageTemp GarbageKind = "temp"
+)
+
+// Garbage is a path found by GC and its size in bytes
+type Garbage struct {
+	Kind GarbageKind
+	Path string
+	Size int64
+}
+
+// DefaultGCMinAge is the MinAge of GC if none is given
+const DefaultGCMinAge = time.Hour
+
+// GCOptions configure GC
+type GCOptions struct {
+	// DryRun reports the garbage without removing it
+	DryRun bool
+	// MinAge is the age below which paths are skipped, as they may belong to an
+	// install in progress. Zero means DefaultGCMinAge, a negative MinAge collects
+	// paths of any age.
+	MinAge time.Duration
+}
+
+// GCReport lists the garbage found by GC, sorted by path
+type GCReport struct {
+	Garbage []Garbage
+	DryRun  bool
+}
+
+// Size returns the reclaimable bytes
+func (r *GCReport) Size() (size int64) {
+	for _, g := range r.Garbage {
+		size += g.Size
+	}
+	return size
+}
+
+// String returns a line per garbage path followed by the total size
+func (r *GCReport) String() string {
+	sb := &strings.Builder{}
+	for _, g := range r.Garbage {
+		fmt.Fprintf(sb, "%-8s %10s %s\n", g.Kind, blob.FormatSize(g.Size), g.Path)
+	}
+	verb := "freed"
+	if r.DryRun {
+		verb = "reclaimable"
+	}
+	fmt.Fprintf(sb, "%s %s\n", blob.FormatSize(r.Size()), verb)
+	return sb.String()
+}
+
+// GC removes install directories of versions which are neither referenced by a
+// receipt nor active, staging directories of interrupted installs and uninstalls
+// in Paths.InstallPath, and stale temporary files in Paths.InstallReceiptsPath and
+// Paths.BinPath. Paths.BlobPath is left to blob.Store.Prune.
+func (i *Installer) GC(opts GCOptions) (*GCReport, error) {
+	report := &GCReport{DryRun: opts.DryRun}
+	if opts.MinAge == 0 {
+		opts.MinAge = DefaultGCMinAge
+	}
+	cutoff := i.now().Add(-opts.MinAge)
+	add := func(kind GarbageKind, path string, fi os.FileInfo) error {
+		if fi.ModTime().After(cutoff) {
+			return nil
+		}
+		size, err := i.size(path)
+		if err != nil {
+			return err
+		}
+		report.Garbage = append(report.Garbage, Garbage{Kind: kind, Path: path, Size: size})
+		return nil
+	}
+
+	plugins, err := readDir(i.fs, i.paths.InstallPath())
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range plugins {
+		path := filepath.Join(i.paths.InstallPath(), fi.Name())
+		if path == filepath.Dir(i.paths.BlobPath()) {
+			continue
+		} else if strings.HasPrefix(fi.Name(), ".") {
+			err = add(GarbageTemp, path, fi)
+		} else if fi.IsDir() {
+			err = i.gcPlugin(fi.Name(), add)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dir := range []string{i.paths.InstallReceiptsPath(), i.paths.BinPath()} {
+		err = afero.Walk(i.fs, dir, func(path string, fi os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil || path == dir || !isTemp(fi.Name()) {
+				return err
+			}
+			if err = add(GarbageTemp, path, fi); err == nil && fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to scan %s", dir)
+		}
+	}
+
+	sort.Slice(report.Garbage, func(a, b int) bool { return report.Garbage[a].Path < report.Garbage[b].Path })
+	if opts.DryRun {
+		return report, nil
+	}
+	for _, g := range report.Garbage {
+		if err = i.fs.RemoveAll(g.Path); err != nil {
+			return report, errors.Wrapf(err, "failed to remove %s", g.Path)
+		}
+	}
+	return report, nil
+}
+
+func (i *Installer) gcPlugin(plugin string, add func(GarbageKind, string, os.FileInfo) error) error {
+	dir := i.paths.PluginInstallPath(plugin)
+	infos, err := readDir(i.fs, dir)
+	if err != nil {
+		return err
+	}
+	active, err := i.active(plugin)
+	if err != nil {
+		return err
+	}
+	current, err := i.Current(plugin)
+	if err != nil && !errors.Is(err, ErrNotInstalled) {
+		return err
+	}
+
+	for _, fi := range infos {
+		name, path := fi.Name(), filepath.Join(dir, fi.Name())
+		switch {
+		case strings.HasPrefix(name, ".stage-"), strings.HasPrefix(name, trashPrefix):
+			err = add(GarbageStaging, path, fi)
+		case strings.HasPrefix(name, "."):
+			err = add(GarbageTemp, path, fi)
+		case name == constants.CurrentLink || !fi.IsDir():
+		case name == current || (active != nil && active.Version == name):
+		default:
+			if _, rerr := i.store.ReadVersion(plugin, name); errors.Is(rerr, receipt.ErrNotFound) {
+				err = add(GarbageVersion, path, fi)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// size returns the bytes of the regular files below path
+func (i *Installer) size(path string) (size int64, err error) {
+	err = afero.Walk(i.fs, path, func(_ string, fi os.FileInfo, err error) error {
+		if err == nil && fi.Mode().IsRegular() {
+			size += fi.Size()
+		}
+		return err
+	})
+	return size, err
+}
+
+// isTemp returns whether name is a temporary file of env.WriteFileAtomic or swapLink
+func isTemp(name string) bool {
+	return strings.HasPrefix(name, ".") && (strings.Contains(name, ".tmp-") || strings.HasSuffix(name, ".tmp"))
+}
+
+func readDir(fs afero.Fs, dir string) ([]os.FileInfo, error) {
+	infos, err := afero.ReadDir(fs, dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", dir)
+	}
+	return infos, nil
+}
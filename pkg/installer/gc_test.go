@@ -0,0 +1,99 @@
+package installer_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/installer"
+)
+
+func TestGC(t *testing.T) {
+	e := envtest.New(t)
+	i, err := installer.NewInstaller(e.Factory, installer.WithClock(func() time.Time { return time.Now().Add(time.Hour) }))
+	require.NoError(t, err)
+	install(t, i, e, map[string][]string{"1.16": {"go"}, "1.17": {"go"}}, "1.16", "1.17")
+
+	plugin := e.Paths.PluginInstallPath("go")
+	garbage := map[string]string{
+		filepath.Join(plugin, "1.15", "bin", "go"):                       "go1.15",
+		filepath.Join(plugin, ".stage-1.18-123", "go.tgz"):               "partial",
+		filepath.Join(plugin, ".uninstall-456", "1.14", "go"):            "go1.14",
+		filepath.Join(e.Paths.InstallReceiptsPath(), ".go.yaml.tmp-789"): "kind: Receipt",
+		filepath.Join(e.Paths.BinPath(), ".go.tmp"):                      "",
+	}
+	for path, content := range garbage {
+		require.NoError(t, e.Fs.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, afero.WriteFile(e.Fs, path, []byte(content), 0644))
+	}
+	// an install in progress is younger than MinAge
+	fresh := filepath.Join(plugin, ".stage-1.19-000")
+	require.NoError(t, e.Fs.Mkdir(fresh, 0755))
+	require.NoError(t, e.Fs.Chtimes(fresh, time.Now().Add(2*time.Hour), time.Now().Add(2*time.Hour)))
+	// the active version is kept without receipt
+	require.NoError(t, e.Fs.Remove(e.Paths.PluginVersionInstallReceiptPath("go", "1.17")))
+
+	report, err := i.GC(installer.GCOptions{DryRun: true, MinAge: 30 * time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, []installer.Garbage{
+		{Kind: installer.GarbageTemp, Path: filepath.Join(e.Paths.BinPath(), ".go.tmp"), Size: 0},
+		{Kind: installer.GarbageTemp, Path: filepath.Join(e.Paths.InstallReceiptsPath(), ".go.yaml.tmp-789"), Size: 13},
+		{Kind: installer.GarbageStaging, Path: filepath.Join(plugin, ".stage-1.18-123"), Size: 7},
+		{Kind: installer.GarbageStaging, Path: filepath.Join(plugin, ".uninstall-456"), Size: 6},
+		{Kind: installer.GarbageVersion, Path: filepath.Join(plugin, "1.15"), Size: 6},
+	}, report.Garbage)
+	assert.Equal(t, int64(32), report.Size())
+	assert.Contains(t, report.String(), "32 B reclaimable\n")
+	for path := range garbage {
+		exists, err := afero.Exists(e.Fs, path)
+		require.NoError(t, err)
+		assert.True(t, exists, "dry run removed %s", path)
+	}
+
+	report, err = i.GC(installer.GCOptions{MinAge: 30 * time.Minute})
+	require.NoError(t, err)
+	assert.Len(t, report.Garbage, 5)
+	assert.Contains(t, report.String(), "32 B freed\n")
+	for path := range garbage {
+		exists, err := afero.Exists(e.Fs, path)
+		require.NoError(t, err)
+		assert.False(t, exists, "%s was not removed", path)
+	}
+	versions, err := i.Versions("go")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "1.16", versions[0].Version)
+	assert.Equal(t, "1.17", versions[1].Version)
+	assert.True(t, versions[1].Active)
+	assertBin(t, e, "go", "go1.17")
+
+	report, err = i.GC(installer.GCOptions{MinAge: 30 * time.Minute})
+	require.NoError(t, err)
+	assert.Empty(t, report.Garbage)
+}
+
+func TestGC_DefaultMinAge(t *testing.T) {
+	e := envtest.New(t)
+	i, err := installer.NewInstaller(e.Factory)
+	require.NoError(t, err)
+
+	plugin := e.Paths.PluginInstallPath("go")
+	running, stale := filepath.Join(plugin, ".stage-1.18-123"), filepath.Join(plugin, ".stage-1.17-456")
+	require.NoError(t, e.Fs.MkdirAll(running, 0755))
+	require.NoError(t, e.Fs.MkdirAll(stale, 0755))
+	now, old := time.Now(), time.Now().Add(-2*installer.DefaultGCMinAge)
+	require.NoError(t, e.Fs.Chtimes(running, now, now))
+	require.NoError(t, e.Fs.Chtimes(stale, old, old))
+
+	report, err := i.GC(installer.GCOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []installer.Garbage{{Kind: installer.GarbageStaging, Path: stale, Size: 0}}, report.Garbage)
+	exists, err := afero.DirExists(e.Fs, running)
+	require.NoError(t, err)
+	assert.True(t, exists, "the staging directory of a running install is kept")
+}
@@ -0,0 +1,243 @@
+// Package installer installs plugin versions described by manifests.
+package installer
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/alex-held/devctl-kit/pkg/blob"
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/log"
+	"github.com/alex-held/devctl-kit/pkg/manifest"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+var (
+	// ErrAlreadyInstalled is returned if the version of a plugin is already installed
+	ErrAlreadyInstalled = errors.New("already installed")
+	// ErrChecksumMismatch is returned if the sha256 of a downloaded artifact does not
+	// match the manifest
+	ErrChecksumMismatch = blob.ErrChecksumMismatch
+)
+
+// Installer downloads, verifies and installs the artifacts of plugin versions
+// for the platform of the factory
+type Installer struct {
+	fs       afero.Fs
+	paths    env.Paths
+	platform system.RuntimeInfo
+	store    *receipt.Store
+	blobs    *blob.Store
+	log      log.Logger
+	client   *http.Client
+	now      func() time.Time
+}
+
+type InstallerOption func(*Installer) *Installer
+
+// WithHTTPClient sets the http.Client artifacts are downloaded with
+func WithHTTPClient(client *http.Client) InstallerOption {
+	return func(i *Installer) *Installer {
+		i.client = client
+		return i
+	}
+}
+
+// WithClock sets the function the install time of receipts is taken from
+func WithClock(now func() time.Time) InstallerOption {
+	return func(i *Installer) *Installer {
+		i.now = now
+		return i
+	}
+}
+
+// NewInstaller returns an Installer using the Fs, Paths, RuntimeInfo and Logger of f
+func NewInstaller(f env.Factory, opts ...InstallerOption) (*Installer, error) {
+	store, err := receipt.NewStore(f)
+	if err != nil {
+		return nil, err
+	}
+	i := &Installer{
+		fs:       f.Fs(),
+		paths:    f.Paths(),
+		platform: f.RuntimeInfo(),
+		store:    store,
+		blobs:    blob.NewStore(f),
+		log:      f.Logger(),
+		client:   http.DefaultClient,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i, nil
+}
+
+// Install installs version of the plugin described by m, or its latest version if
+// version is empty. The artifact is fetched into the blob store, unless it is stored
+// already, and extracted from there into a staging directory next to
+// Paths.PluginVersionInstallPath, which is moved into place. Then the receipt of the
+// version is written and it becomes the active version, unless the plugin is pinned.
+// If any step fails, the completed ones are rolled back.
+func (i *Installer) Install(m *manifest.Manifest, version string) (_ *receipt.Receipt, err error) {
+	v, artifact, err := i.resolve(m, version)
+	if err != nil {
+		return nil, err
+	}
+	dest := i.paths.PluginVersionInstallPath(m.Name, v.Version)
+	if exists, err := env.Exists(i.fs, dest); err != nil {
+		return nil, errors.Wrapf(err, "failed to stat %s", dest)
+	} else if exists {
+		return nil, errors.Wrapf(ErrAlreadyInstalled, "%s %s", m.Name, v.Version)
+	}
+
+	undo := &rollback{}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rerr := undo.run(); rerr != nil {
+			err = errors.Errorf("%v, rollback failed: %v", err, rerr)
+		}
+	}()
+
+	pluginDir := i.paths.PluginInstallPath(m.Name)
+	if exists, _ := afero.DirExists(i.fs, pluginDir); !exists {
+		undo.add(func() error { return i.fs.RemoveAll(pluginDir) })
+	}
+	if err = i.fs.MkdirAll(pluginDir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", pluginDir)
+	}
+	staging, err := afero.TempDir(i.fs, pluginDir, ".stage-"+v.Version+"-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a staging directory")
+	}
+	defer i.fs.RemoveAll(staging)
+
+	sum := strings.ToLower(artifact.SHA256)
+	if err = i.fetch(artifact.URI, sum); err != nil {
+		return nil, err
+	}
+	root := filepath.Join(staging, "root")
+	if err = i.extract(artifact.URI, i.blobs.Path(sum), root); err != nil {
+		return nil, errors.Wrapf(err, "failed to extract %s", artifact.URI)
+	}
+	r := receipt.New(m.Name, v.Version, i.platform)
+	r.Source = receipt.Source{Index: m.Index, URI: artifact.URI}
+	r.Checksum = sum
+	r.InstalledAt = i.now().UTC().Truncate(time.Second)
+	for _, bin := range artifact.Bin {
+		if err = i.checkBin(root, bin); err != nil {
+			return nil, err
+		}
+		r.Bin = append(r.Bin, receipt.Bin{Name: bin.Name, Path: bin.Path})
+	}
+	if r.Files, err = i.files(root); err != nil {
+		return nil, err
+	}
+
+	if err = i.fs.Rename(root, dest); err != nil {
+		return nil, errors.Wrapf(err, "failed to move %s %s into place", m.Name, v.Version)
+	}
+	undo.add(func() error { return i.fs.RemoveAll(dest) })
+
+	if err = i.store.WriteVersion(r); err != nil {
+		return nil, err
+	}
+	undo.add(func() error { return i.store.DeleteVersion(m.Name, v.Version) })
+
+	active, err := i.active(m.Name)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil && active.Pinned {
+		i.log.Infof("installed %s %s, %s stays active as it is pinned", m.Name, v.Version, active.Version)
+		return r, nil
+	}
+	if err = i.activate(r, active, undo); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// resolve returns the version of m and its artifact for the platform of the Installer
+func (i *Installer) resolve(m *manifest.Manifest, version string) (*manifest.Version, *manifest.Artifact, error) {
+	if m.Index == "" {
+		return nil, nil, errors.Errorf("manifest of plugin %s was not loaded from an index", m.Name)
+	}
+	v, ok := m.Latest()
+	if version != "" {
+		v, ok = m.Version(version)
+	}
+	if !ok {
+		return nil, nil, errors.Errorf("plugin %s has no version %q", m.Name, version)
+	}
+	if v.Version == constants.CurrentLink {
+		return nil, nil, errors.Errorf("plugin %s has the reserved version %q", m.Name, v.Version)
+	}
+	artifact, ok := v.Artifact(i.platform)
+	if !ok {
+		return nil, nil, errors.Errorf("plugin %s %s is not available for %s/%s", m.Name, v.Version, i.platform.OS, i.platform.Arch)
+	}
+	return v, artifact, nil
+}
+
+// checkBin makes sure the executable of bin exists in the extracted artifact
+func (i *Installer) checkBin(root string, bin manifest.Bin) error {
+	path := filepath.Join(root, filepath.FromSlash(bin.Path))
+	fi, err := i.fs.Stat(path)
+	if err != nil || fi.IsDir() {
+		return errors.Errorf("executable %s of bin %s is missing in the artifact", bin.Path, bin.Name)
+	}
+	if fi.Mode().Perm()&0111 == 0 {
+		return i.fs.Chmod(path, fi.Mode().Perm()|0755)
+	}
+	return nil
+}
+
+// files returns the regular files below dir with their checksums, sorted by path
+func (i *Installer) files(dir string) ([]receipt.File, error) {
+	var files []receipt.File
+	err := afero.Walk(i.fs, dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sum, err := receipt.Checksum(i.fs, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, receipt.File{Path: filepath.ToSlash(rel), SHA256: sum})
+		return nil
+	})
+	sort.Slice(files, func(a, b int) bool { return files[a].Path < files[b].Path })
+	return files, err
+}
+
+// rollback undoes the completed steps of an installation in reverse order
+type rollback []func() error
+
+func (r *rollback) add(step func() error) { *r = append(*r, step) }
+
+func (r rollback) run() error {
+	var errs []error
+	for i := len(r) - 1; i >= 0; i-- {
+		if err := r[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,323 @@
+package installer_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/installer"
+	"github.com/alex-held/devctl-kit/pkg/manifest"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+)
+
+var clock = func() time.Time { return time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC) }
+
+type entry struct {
+	name, body, link string
+}
+
+func tarGz(t *testing.T, entries ...entry) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Mode: 0755, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.link != "" {
+			h.Typeflag, h.Linkname, h.Size = tar.TypeSymlink, e.link, 0
+		}
+		require.NoError(t, tw.WriteHeader(h))
+		_, err := tw.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, entries ...entry) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		h := &zip.FileHeader{Name: e.name}
+		h.SetMode(0755)
+		w, err := zw.CreateHeader(h)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func sum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// serve serves the artifacts by path and returns the base url of the server
+func serve(t *testing.T, artifacts map[string][]byte) string {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := artifacts[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	t.Cleanup(s.Close)
+	return s.URL
+}
+
+func newManifest(versions ...manifest.Version) *manifest.Manifest {
+	return &manifest.Manifest{
+		APIVersion: manifest.APIVersion,
+		Kind:       manifest.Kind,
+		Name:       "go",
+		Versions:   versions,
+		Index:      "default",
+	}
+}
+
+func version(v, uri, sha256 string, bins ...manifest.Bin) manifest.Version {
+	return manifest.Version{Version: v, Artifacts: []manifest.Artifact{{
+		Platform: manifest.Platform{OS: envtest.DefaultRuntimeInfo.OS, Arch: envtest.DefaultRuntimeInfo.Arch},
+		URI:      uri,
+		SHA256:   sha256,
+		Bin:      bins,
+	}}}
+}
+
+func newInstaller(t *testing.T) (*installer.Installer, *envtest.Env) {
+	e := envtest.New(t)
+	i, err := installer.NewInstaller(e.Factory, installer.WithClock(clock))
+	require.NoError(t, err)
+	return i, e
+}
+
+func readReceipt(t *testing.T, e *envtest.Env) *receipt.Receipt {
+	s, err := receipt.NewStore(e.Factory)
+	require.NoError(t, err)
+	r, err := s.Read("go")
+	require.NoError(t, err)
+	return r
+}
+
+// assertClean asserts that nothing is left of plugin go
+func assertClean(t *testing.T, e *envtest.Env) {
+	for _, path := range []string{e.Paths.PluginInstallPath("go"), e.Paths.PluginInstallReceiptPath("go"), e.Paths.Bin("go")} {
+		_, err := env.Lstat(e.Fs, path)
+		assert.True(t, os.IsNotExist(err), "%s exists", path)
+	}
+}
+
+func TestInstall_HTTP(t *testing.T) {
+	archive := tarGz(t,
+		entry{name: "go/bin/go", body: "go1.17"},
+		entry{name: "go/VERSION", body: "1.17"},
+		entry{name: "go/bin/current", link: "go"},
+	)
+	url := serve(t, map[string][]byte{"/go1.17.tar.gz": archive})
+	i, e := newInstaller(t)
+
+	r, err := i.Install(newManifest(version("1.17", url+"/go1.17.tar.gz", sum(archive), manifest.Bin{Name: "go", Path: "go/bin/go"})), "")
+	require.NoError(t, err)
+
+	dest := e.Paths.PluginVersionInstallPath("go", "1.17")
+	b, err := afero.ReadFile(e.Fs, e.Paths.Bin("go"))
+	require.NoError(t, err)
+	assert.Equal(t, "go1.17", string(b))
+	target, err := env.Readlink(e.Fs, e.Paths.Bin("go"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(e.Paths.PluginCurrentPath("go"), "go", "bin", "go"), target)
+	target, err = env.Readlink(e.Fs, filepath.Join(dest, "go", "bin", "current"))
+	require.NoError(t, err)
+	assert.Equal(t, "go", target)
+
+	assert.Equal(t, &receipt.Receipt{
+		APIVersion:  receipt.APIVersion,
+		Kind:        receipt.Kind,
+		Plugin:      "go",
+		Version:     "1.17",
+		Source:      receipt.Source{Index: "default", URI: url + "/go1.17.tar.gz"},
+		Platform:    receipt.Platform{OS: "linux", Arch: "amd64"},
+		Checksum:    sum(archive),
+		Files:       []receipt.File{{Path: "go/VERSION", SHA256: sum([]byte("1.17"))}, {Path: "go/bin/go", SHA256: sum([]byte("go1.17"))}},
+		Bin:         []receipt.Bin{{Name: "go", Path: "go/bin/go"}},
+		InstalledAt: clock(),
+	}, r)
+	assert.Equal(t, r, readReceipt(t, e))
+
+	infos, err := afero.ReadDir(e.Fs, e.Paths.PluginInstallPath("go"))
+	require.NoError(t, err)
+	require.Len(t, infos, 2, "the staging directory is removed")
+	assert.Equal(t, "1.17", infos[0].Name())
+	assert.Equal(t, "current", infos[1].Name())
+
+	_, err = i.Install(newManifest(version("1.17", url+"/go1.17.tar.gz", sum(archive))), "1.17")
+	assert.True(t, errors.Is(err, installer.ErrAlreadyInstalled))
+}
+
+func TestInstall_File(t *testing.T) {
+	i, e := newInstaller(t)
+	bin, archive := []byte("#!/bin/sh"), zipped(t, entry{name: "bin/gofmt", body: "gofmt"})
+	require.NoError(t, afero.WriteFile(e.Fs, "/artifacts/go", bin, 0644))
+	require.NoError(t, afero.WriteFile(e.Fs, "/artifacts/go.zip", archive, 0644))
+
+	m := newManifest(
+		version("1.16", "file:///artifacts/go", sum(bin), manifest.Bin{Name: "go", Path: "go"}),
+		version("1.17", "file:///artifacts/go.zip", sum(archive), manifest.Bin{Name: "gofmt", Path: "bin/gofmt"}),
+	)
+	_, err := i.Install(m, "1.16")
+	require.NoError(t, err)
+	fi, err := e.Fs.Stat(e.Paths.Bin("go"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), fi.Mode().Perm())
+
+	r, err := i.Install(m, "1.17")
+	require.NoError(t, err)
+	assert.Equal(t, []receipt.File{{Path: "bin/gofmt", SHA256: sum([]byte("gofmt"))}}, r.Files)
+	b, err := afero.ReadFile(e.Fs, e.Paths.Bin("gofmt"))
+	require.NoError(t, err)
+	assert.Equal(t, "gofmt", string(b))
+}
+
+func TestInstall_Store(t *testing.T) {
+	i, e := newInstaller(t)
+	archive := tarGz(t, entry{name: "go", body: "go1.17"})
+	require.NoError(t, afero.WriteFile(e.Fs, "/artifacts/go.tgz", archive, 0644))
+	m := newManifest(version("1.17", "file:///artifacts/go.tgz", sum(archive), manifest.Bin{Name: "go", Path: "go"}))
+	_, err := i.Install(m, "")
+	require.NoError(t, err)
+	b, err := afero.ReadFile(e.Fs, e.Paths.Blob(sum(archive)))
+	require.NoError(t, err)
+	assert.Equal(t, archive, b)
+
+	// another profile installs the stored artifact without downloading it
+	require.NoError(t, e.Fs.Remove("/artifacts/go.tgz"))
+	work := envtest.New(t, env.WithFs(e.Fs), env.WithPaths(e.Paths.WithProfile("work")))
+	w, err := installer.NewInstaller(work.Factory, installer.WithClock(clock))
+	require.NoError(t, err)
+	_, err = w.Install(m, "")
+	require.NoError(t, err)
+	assertBin(t, work, "go", "go1.17")
+
+	// a corrupt artifact is downloaded again
+	require.NoError(t, afero.WriteFile(e.Fs, e.Paths.Blob(sum(archive)), []byte("corrupt"), 0644))
+	require.NoError(t, i.Uninstall("go", ""))
+	_, err = i.Install(m, "")
+	assert.Error(t, err)
+	assertClean(t, e)
+	exists, err := afero.Exists(e.Fs, e.Paths.Blob(sum(archive)))
+	require.NoError(t, err)
+	assert.False(t, exists, "the corrupt artifact is removed")
+}
+
+func TestInstall_Upgrade(t *testing.T) {
+	old, next := tarGz(t, entry{name: "go", body: "old"}), tarGz(t, entry{name: "go", body: "new"})
+	url := serve(t, map[string][]byte{"/old.tgz": old, "/new.tgz": next})
+	i, e := newInstaller(t)
+	m := newManifest(
+		version("1.16", url+"/old.tgz", sum(old), manifest.Bin{Name: "go", Path: "go"}),
+		version("1.17", url+"/new.tgz", sum(next), manifest.Bin{Name: "go", Path: "go"}),
+	)
+	_, err := i.Install(m, "1.16")
+	require.NoError(t, err)
+	_, err = i.Install(m, "1.17")
+	require.NoError(t, err)
+
+	b, err := afero.ReadFile(e.Fs, e.Paths.Bin("go"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(b))
+	assert.Equal(t, "1.17", readReceipt(t, e).Version)
+}
+
+func TestInstall_ChecksumMismatch(t *testing.T) {
+	archive := tarGz(t, entry{name: "go", body: "go"})
+	url := serve(t, map[string][]byte{"/go.tgz": archive})
+	i, e := newInstaller(t)
+
+	_, err := i.Install(newManifest(version("1.17", url+"/go.tgz", sum([]byte("other")), manifest.Bin{Name: "go", Path: "go"})), "")
+	assert.True(t, errors.Is(err, installer.ErrChecksumMismatch), "got %v", err)
+	assertClean(t, e)
+}
+
+func TestInstall_Errors(t *testing.T) {
+	evil := tarGz(t, entry{name: "../../evil", body: "evil"})
+	escaping := tarGz(t, entry{name: "dir/link", link: "../../.."})
+	chained := tarGz(t, entry{name: "a", link: "."}, entry{name: "a/b", link: ".."})
+	later := tarGz(t, entry{name: "b", link: "a/.."}, entry{name: "a", link: "."}, entry{name: "b/evil", body: "evil"})
+	noBin := tarGz(t, entry{name: "go", body: "go"})
+	url := serve(t, map[string][]byte{
+		"/evil.tgz": evil, "/escaping.tgz": escaping, "/chained.tgz": chained, "/later.tgz": later, "/nobin.tgz": noBin,
+	})
+
+	tests := map[string]manifest.Version{
+		"entry outside of the archive": version("1.17", url+"/evil.tgz", sum(evil)),
+		"link outside of the archive":  version("1.17", url+"/escaping.tgz", sum(escaping)),
+		"chained links":                version("1.17", url+"/chained.tgz", sum(chained)),
+		"link through a later link":    version("1.17", url+"/later.tgz", sum(later)),
+		"missing executable":           version("1.17", url+"/nobin.tgz", sum(noBin), manifest.Bin{Name: "gofmt", Path: "gofmt"}),
+		"not found":                    version("1.17", url+"/missing.tgz", sum(noBin)),
+		"unsupported scheme":           version("1.17", "ftp://example.com/go.tgz", sum(noBin)),
+	}
+	for name, v := range tests {
+		t.Run(name, func(t *testing.T) {
+			i, e := newInstaller(t)
+			_, err := i.Install(newManifest(v), "")
+			assert.Error(t, err)
+			assertClean(t, e)
+		})
+	}
+
+	i, _ := newInstaller(t)
+	_, err := i.Install(newManifest(version("1.17", url+"/nobin.tgz", sum(noBin))), "1.18")
+	assert.EqualError(t, err, `plugin go has no version "1.18"`)
+	m := newManifest(version("1.17", url+"/nobin.tgz", sum(noBin)))
+	m.Versions[0].Artifacts[0].Platform.OS = "darwin"
+	_, err = i.Install(m, "")
+	assert.EqualError(t, err, "plugin go 1.17 is not available for linux/amd64")
+}
+
+func TestInstall_Rollback(t *testing.T) {
+	old, next := tarGz(t, entry{name: "go", body: "old"}), tarGz(t, entry{name: "go", body: "new"}, entry{name: "gofmt", body: "gofmt"})
+	url := serve(t, map[string][]byte{"/old.tgz": old, "/new.tgz": next})
+	i, e := newInstaller(t)
+	m := newManifest(
+		version("1.16", url+"/old.tgz", sum(old), manifest.Bin{Name: "go", Path: "go"}),
+		version("1.17", url+"/new.tgz", sum(next), manifest.Bin{Name: "go", Path: "go"}, manifest.Bin{Name: "gofmt", Path: "gofmt"}),
+	)
+	_, err := i.Install(m, "1.16")
+	require.NoError(t, err)
+	// gofmt is owned by another plugin, so linking it fails after go was relinked
+	require.NoError(t, afero.WriteFile(e.Fs, e.Paths.Bin("gofmt"), []byte("other"), 0755))
+
+	_, err = i.Install(m, "1.17")
+	assert.EqualError(t, err, "bin "+e.Paths.Bin("gofmt")+" already exists and is not a link to plugin go")
+
+	b, err := afero.ReadFile(e.Fs, e.Paths.Bin("go"))
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(b), "the link of the previous version is restored")
+	b, err = afero.ReadFile(e.Fs, e.Paths.Bin("gofmt"))
+	require.NoError(t, err)
+	assert.Equal(t, "other", string(b))
+	assert.Equal(t, "1.16", readReceipt(t, e).Version)
+	exists, err := afero.Exists(e.Fs, e.Paths.PluginVersionInstallPath("go", "1.17"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,140 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+)
+
+// trashPrefix is the prefix of the directories versions are moved to before they are
+// removed by Uninstall
+const trashPrefix = ".uninstall-"
+
+// Uninstall removes version of plugin, or all of its versions if version is empty.
+//
+// The versions are unhooked first: if the active version is removed, the highest
+// remaining version becomes active, or the links in Paths.BinPath, the current link
+// and the receipt of the plugin are removed. Then the receipts of the versions are
+// removed and their install directories are moved aside and deleted, so that an
+// interrupted uninstall leaves garbage for GC rather than dangling links.
+// It fails with ErrPinned if the pinned version would be removed.
+func (i *Installer) Uninstall(plugin, version string) (err error) {
+	installed, err := i.Versions(plugin)
+	if err != nil {
+		return err
+	}
+	active, err := i.active(plugin)
+	if err != nil {
+		return err
+	}
+
+	var targets, remaining []InstalledVersion
+	for _, v := range installed {
+		if version == "" || v.Version == version {
+			targets = append(targets, v)
+		} else {
+			remaining = append(remaining, v)
+		}
+	}
+	if len(targets) == 0 {
+		return errors.Wrapf(ErrNotInstalled, "plugin %s %s", plugin, version)
+	}
+
+	undo := &rollback{}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rerr := undo.run(); rerr != nil {
+			err = errors.Errorf("%v, rollback failed: %v", err, rerr)
+		}
+	}()
+
+	for _, v := range targets {
+		if !v.Active {
+			continue
+		}
+		if v.Pinned {
+			return errors.Wrapf(ErrPinned, "plugin %s is pinned to %s, unpin it first", plugin, v.Version)
+		}
+		if err = i.replaceActive(plugin, active, remaining, undo); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range targets {
+		if v.Receipt == nil {
+			continue
+		}
+		if err = i.store.DeleteVersion(plugin, v.Version); err != nil {
+			return err
+		}
+		r := v.Receipt
+		undo.add(func() error { return i.store.WriteVersion(r) })
+	}
+
+	pluginDir := i.paths.PluginInstallPath(plugin)
+	trash, err := afero.TempDir(i.fs, pluginDir, trashPrefix)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary directory")
+	}
+	undo.add(func() error { return i.fs.RemoveAll(trash) })
+	for _, v := range targets {
+		dir, moved := i.paths.PluginVersionInstallPath(plugin, v.Version), filepath.Join(trash, v.Version)
+		if err = i.fs.Rename(dir, moved); err != nil {
+			return errors.Wrapf(err, "failed to remove %s %s", plugin, v.Version)
+		}
+		undo.add(func() error { return i.fs.Rename(moved, dir) })
+	}
+
+	// the versions are unhooked, failing to delete their files leaves garbage only
+	if len(remaining) == 0 {
+		trash = pluginDir
+		if rerr := i.fs.RemoveAll(filepath.Join(i.paths.InstallReceiptsPath(), plugin)); rerr != nil {
+			i.log.Warnf("failed to remove the receipts of %s: %v", plugin, rerr)
+		}
+	}
+	if rerr := i.fs.RemoveAll(trash); rerr != nil {
+		i.log.Warnf("failed to remove %s, it is removed by the next gc: %v", trash, rerr)
+	}
+	return nil
+}
+
+// replaceActive makes the highest of the remaining versions with a receipt active, or
+// deactivates the plugin if there is none
+func (i *Installer) replaceActive(plugin string, active *receipt.Receipt, remaining []InstalledVersion, undo *rollback) error {
+	for k := len(remaining) - 1; k >= 0; k-- {
+		if remaining[k].Receipt != nil {
+			return i.activate(remaining[k].Receipt, active, undo)
+		}
+	}
+
+	if active != nil {
+		for _, bin := range active.Bin {
+			if err := i.unlink(plugin, bin.Name, undo); err != nil {
+				return err
+			}
+		}
+	}
+	current := i.paths.PluginCurrentPath(plugin)
+	if target, err := env.Readlink(i.fs, current); err == nil {
+		if err = i.fs.Remove(current); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", current)
+		}
+		undo.add(func() error { return env.Symlink(i.fs, target, current) })
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if active != nil {
+		if err := i.store.Delete(plugin); err != nil {
+			return err
+		}
+		undo.add(func() error { return i.store.Write(active) })
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package installer_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/installer"
+)
+
+func TestUninstall_Version(t *testing.T) {
+	i, e := newInstaller(t)
+	install(t, i, e, map[string][]string{"1.16": {"go"}, "1.17": {"go", "gofmt"}}, "1.16", "1.17")
+
+	require.NoError(t, i.Uninstall("go", "1.17"))
+	versions, active, _ := versionsOf(t, i)
+	assert.Equal(t, []string{"1.16"}, versions)
+	assert.Equal(t, "1.16", active, "the highest remaining version becomes active")
+	assertBin(t, e, "go", "go1.16")
+	exists, err := afero.Exists(e.Fs, e.Paths.Bin("gofmt"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = afero.Exists(e.Fs, e.Paths.PluginVersionInstallReceiptPath("go", "1.17"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, "1.16", readReceipt(t, e).Version)
+
+	infos, err := afero.ReadDir(e.Fs, e.Paths.PluginInstallPath("go"))
+	require.NoError(t, err)
+	require.Len(t, infos, 2, "nothing is left of 1.17")
+
+	err = i.Uninstall("go", "1.17")
+	assert.True(t, errors.Is(err, installer.ErrNotInstalled), "got %v", err)
+}
+
+func TestUninstall_All(t *testing.T) {
+	i, e := newInstaller(t)
+	install(t, i, e, map[string][]string{"1.16": {"go"}, "1.17": {"go", "gofmt"}}, "1.16", "1.17")
+
+	require.NoError(t, i.Uninstall("go", ""))
+	assertClean(t, e)
+	for _, path := range []string{e.Paths.Bin("gofmt"), filepath.Join(e.Paths.InstallReceiptsPath(), "go")} {
+		exists, err := afero.Exists(e.Fs, path)
+		require.NoError(t, err)
+		assert.False(t, exists, "%s exists", path)
+	}
+	_, err := e.Paths.Verify(e.Fs)
+	if err != nil {
+		assert.NotContains(t, err.Error(), "dangling")
+	}
+
+	err = i.Uninstall("go", "")
+	assert.True(t, errors.Is(err, installer.ErrNotInstalled), "got %v", err)
+}
+
+func TestUninstall_Pinned(t *testing.T) {
+	i, e := newInstaller(t)
+	install(t, i, e, map[string][]string{"1.16": {"go"}, "1.17": {"go"}}, "1.16", "1.17")
+	require.NoError(t, i.Pin("go", "1.16"))
+
+	err := i.Uninstall("go", "1.16")
+	assert.True(t, errors.Is(err, installer.ErrPinned), "got %v", err)
+	err = i.Uninstall("go", "")
+	assert.True(t, errors.Is(err, installer.ErrPinned), "got %v", err)
+	versions, _, pinned := versionsOf(t, i)
+	assert.Equal(t, []string{"1.16", "1.17"}, versions)
+	assert.Equal(t, "1.16", pinned)
+
+	require.NoError(t, i.Uninstall("go", "1.17"))
+	versions, active, _ := versionsOf(t, i)
+	assert.Equal(t, []string{"1.16"}, versions)
+	assert.Equal(t, "1.16", active)
+	assertBin(t, e, "go", "go1.16")
+}
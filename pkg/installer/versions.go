@@ -0,0 +1,348 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/manifest"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+)
+
+var (
+	// ErrNotInstalled is returned if a plugin or version is not installed
+	ErrNotInstalled = errors.New("not installed")
+	// ErrPinned is returned when switching the version of a pinned plugin
+	ErrPinned = errors.New("pinned")
+)
+
+// InstalledVersion is a version of a plugin in Paths.PluginInstallPath
+type InstalledVersion struct {
+	Version string
+	// Active is set for the version Paths.PluginCurrentPath links to
+	Active bool
+	// Pinned is set for the active version of a pinned plugin
+	Pinned bool
+	// Receipt is nil for versions installed without a receipt
+	Receipt *receipt.Receipt
+}
+
+// Link is a symbolic link in Paths.BinPath to an executable of a plugin
+type Link struct {
+	Name   string
+	Path   string
+	Target string
+	Plugin string
+	// Version is the version the link resolves to, which is empty if the link or
+	// the current version of the plugin is dangling
+	Version string
+}
+
+// Versions returns the installed versions of plugin in increasing order, see
+// manifest.CompareVersions
+func (i *Installer) Versions(plugin string) ([]InstalledVersion, error) {
+	if err := receipt.ValidatePluginName(plugin); err != nil {
+		return nil, err
+	}
+	dir := i.paths.PluginInstallPath(plugin)
+	infos, err := afero.ReadDir(i.fs, dir)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(ErrNotInstalled, "plugin %s", plugin)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", dir)
+	}
+	current, err := i.Current(plugin)
+	if err != nil && !errors.Is(err, ErrNotInstalled) {
+		return nil, err
+	}
+	active, err := i.active(plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []InstalledVersion
+	for _, fi := range infos {
+		name := fi.Name()
+		if !fi.IsDir() || name == constants.CurrentLink || strings.HasPrefix(name, ".") {
+			continue
+		}
+		v := InstalledVersion{Version: name, Active: name == current}
+		v.Pinned = v.Active && active != nil && active.Pinned
+		if v.Receipt, err = i.store.ReadVersion(plugin, name); err != nil && !errors.Is(err, receipt.ErrNotFound) {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(a, b int) bool {
+		return manifest.CompareVersions(versions[a].Version, versions[b].Version) < 0
+	})
+	return versions, nil
+}
+
+// Current returns the active version of plugin, i.e. the target of Paths.PluginCurrentPath
+func (i *Installer) Current(plugin string) (string, error) {
+	target, err := env.Readlink(i.fs, i.paths.PluginCurrentPath(plugin))
+	if os.IsNotExist(err) {
+		return "", errors.Wrapf(ErrNotInstalled, "plugin %s has no active version", plugin)
+	} else if err != nil {
+		return "", errors.Wrapf(err, "failed to read the active version of plugin %s", plugin)
+	}
+	return filepath.Base(target), nil
+}
+
+// Use makes version the active version of plugin. It fails with ErrPinned if the
+// plugin is pinned to another version.
+func (i *Installer) Use(plugin, version string) (err error) {
+	r, err := i.installed(plugin, version)
+	if err != nil {
+		return err
+	}
+	active, err := i.active(plugin)
+	if err != nil {
+		return err
+	}
+	if active != nil && active.Pinned {
+		if active.Version == version {
+			return nil
+		}
+		return errors.Wrapf(ErrPinned, "plugin %s is pinned to %s", plugin, active.Version)
+	}
+	return i.switchTo(r, active)
+}
+
+// Pin makes version the active version of plugin and pins it, so that neither Install
+// nor Use switch the version. An empty version pins the active version.
+func (i *Installer) Pin(plugin, version string) error {
+	active, err := i.active(plugin)
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		if active == nil {
+			return errors.Wrapf(ErrNotInstalled, "plugin %s has no active version", plugin)
+		}
+		version = active.Version
+	}
+	r, err := i.installed(plugin, version)
+	if err != nil {
+		return err
+	}
+	r.Pinned = true
+	return i.switchTo(r, active)
+}
+
+// Unpin unpins the active version of plugin
+func (i *Installer) Unpin(plugin string) error {
+	active, err := i.active(plugin)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		return errors.Wrapf(ErrNotInstalled, "plugin %s has no active version", plugin)
+	}
+	if !active.Pinned {
+		return nil
+	}
+	active.Pinned = false
+	return i.store.Write(active)
+}
+
+// Which returns the plugin and version the link name in Paths.BinPath resolves to
+func (i *Installer) Which(name string) (*Link, error) {
+	path := i.paths.Bin(name)
+	target, err := env.Readlink(i.fs, path)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(ErrNotInstalled, "bin %s", name)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a link to a plugin", path)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	rel, err := filepath.Rel(i.paths.InstallPath(), target)
+	if err != nil || !within(i.paths.InstallPath(), target) || !strings.Contains(rel, string(filepath.Separator)) {
+		return nil, errors.Errorf("%s is not a link to a plugin", path)
+	}
+
+	elems := strings.SplitN(rel, string(filepath.Separator), 3)
+	link := &Link{Name: name, Path: path, Target: target, Plugin: elems[0], Version: elems[1]}
+	if link.Version == constants.CurrentLink {
+		if link.Version, err = i.Current(link.Plugin); err != nil && !errors.Is(err, ErrNotInstalled) {
+			return nil, err
+		}
+	}
+	if exists, err := env.Exists(i.fs, path); err != nil || !exists {
+		link.Version = ""
+	}
+	return link, nil
+}
+
+// Links returns the links in Paths.BinPath to executables of plugins, sorted by name.
+// Other files are skipped.
+func (i *Installer) Links() ([]*Link, error) {
+	infos, err := afero.ReadDir(i.fs, i.paths.BinPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", i.paths.BinPath())
+	}
+	var links []*Link
+	for _, fi := range infos {
+		if fi.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		if link, err := i.Which(fi.Name()); err == nil {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// installed returns the receipt of version of plugin, which must be installed
+func (i *Installer) installed(plugin, version string) (*receipt.Receipt, error) {
+	if version == "" || version == constants.CurrentLink || strings.ContainsAny(version, `/\`) {
+		return nil, errors.Errorf("invalid version %q", version)
+	}
+	exists, err := afero.DirExists(i.fs, i.paths.PluginVersionInstallPath(plugin, version))
+	if err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, errors.Wrapf(ErrNotInstalled, "plugin %s %s", plugin, version)
+	}
+	return i.store.ReadVersion(plugin, version)
+}
+
+// active returns the receipt of the active version of plugin or nil
+func (i *Installer) active(plugin string) (*receipt.Receipt, error) {
+	r, err := i.store.Read(plugin)
+	if errors.Is(err, receipt.ErrNotFound) {
+		return nil, nil
+	}
+	return r, err
+}
+
+// switchTo activates r and rolls back if it fails
+func (i *Installer) switchTo(r, active *receipt.Receipt) (err error) {
+	undo := &rollback{}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rerr := undo.run(); rerr != nil {
+			err = errors.Errorf("%v, rollback failed: %v", err, rerr)
+		}
+	}()
+	return i.activate(r, active, undo)
+}
+
+// activate makes r the active version of its plugin: Paths.PluginCurrentPath is
+// swapped to link to its version, its executables are linked into Paths.BinPath
+// through Paths.PluginCurrentPath, the links of executables of the previously active
+// version which r does not provide are removed, and r is written as the receipt of
+// the plugin.
+func (i *Installer) activate(r, previous *receipt.Receipt, undo *rollback) error {
+	current := i.paths.PluginCurrentPath(r.Plugin)
+	if err := i.swapLink(current, r.Version, undo); err != nil {
+		return errors.Wrapf(err, "failed to activate %s %s", r.Plugin, r.Version)
+	}
+
+	bins := map[string]bool{}
+	for _, bin := range r.Bin {
+		bins[bin.Name] = true
+		if err := i.link(r.Plugin, filepath.Join(current, filepath.FromSlash(bin.Path)), bin.Name, undo); err != nil {
+			return err
+		}
+	}
+	if previous != nil {
+		for _, bin := range previous.Bin {
+			if bins[bin.Name] {
+				continue
+			}
+			if err := i.unlink(r.Plugin, bin.Name, undo); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := i.store.Write(r); err != nil {
+		return err
+	}
+	undo.add(func() error {
+		if previous != nil {
+			return i.store.Write(previous)
+		}
+		return i.store.Delete(r.Plugin)
+	})
+	return nil
+}
+
+// link links the executable target into Paths.BinPath as name. A link to another
+// executable of the plugin is replaced, other files are not.
+func (i *Installer) link(plugin, target, name string, undo *rollback) error {
+	path := i.paths.Bin(name)
+	if err := i.fs.MkdirAll(i.paths.BinPath(), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create %s", i.paths.BinPath())
+	}
+	if _, err := env.Lstat(i.fs, path); err == nil {
+		if previous, err := env.Readlink(i.fs, path); err != nil || !within(i.paths.PluginInstallPath(plugin), previous) {
+			return errors.Errorf("bin %s already exists and is not a link to plugin %s", path, plugin)
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to stat %s", path)
+	}
+	if err := i.swapLink(path, target, undo); err != nil {
+		return errors.Wrapf(err, "failed to link %s", path)
+	}
+	return nil
+}
+
+// unlink removes the link name from Paths.BinPath if it links to the plugin
+func (i *Installer) unlink(plugin, name string, undo *rollback) error {
+	path := i.paths.Bin(name)
+	previous, err := env.Readlink(i.fs, path)
+	if err != nil || !within(i.paths.PluginInstallPath(plugin), previous) {
+		return nil
+	}
+	if err = i.fs.Remove(path); err != nil {
+		return errors.Wrapf(err, "failed to remove %s", path)
+	}
+	undo.add(func() error { return env.Symlink(i.fs, previous, path) })
+	return nil
+}
+
+// swapLink atomically replaces the symbolic link at path with a link to target by
+// renaming a temporary link over it
+func (i *Installer) swapLink(path, target string, undo *rollback) error {
+	previous, err := env.Readlink(i.fs, path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if previous == target {
+		return nil
+	}
+
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err = i.fs.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err = env.Symlink(i.fs, target, tmp); err != nil {
+		return err
+	}
+	if err = i.fs.Rename(tmp, path); err != nil {
+		_ = i.fs.Remove(tmp)
+		return err
+	}
+	undo.add(func() error {
+		if previous == "" {
+			return i.fs.Remove(path)
+		}
+		return i.swapLink(path, previous, &rollback{})
+	})
+	return nil
+}
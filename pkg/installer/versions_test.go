@@ -0,0 +1,158 @@
+package installer_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/installer"
+	"github.com/alex-held/devctl-kit/pkg/manifest"
+)
+
+// install installs the versions of plugin go, each providing the executables of bins
+func install(t *testing.T, i *installer.Installer, e *envtest.Env, bins map[string][]string, versions ...string) {
+	t.Helper()
+	var mvs []manifest.Version
+	for _, v := range versions {
+		var entries []entry
+		var mbins []manifest.Bin
+		for _, bin := range bins[v] {
+			entries = append(entries, entry{name: "bin/" + bin, body: bin + v})
+			mbins = append(mbins, manifest.Bin{Name: bin, Path: "bin/" + bin})
+		}
+		archive := tarGz(t, entries...)
+		require.NoError(t, afero.WriteFile(e.Fs, "/artifacts/"+v+".tgz", archive, 0644))
+		mvs = append(mvs, version(v, "file:///artifacts/"+v+".tgz", sum(archive), mbins...))
+	}
+	m := newManifest(mvs...)
+	for _, v := range versions {
+		_, err := i.Install(m, v)
+		require.NoError(t, err)
+	}
+}
+
+func assertBin(t *testing.T, e *envtest.Env, name, content string) {
+	t.Helper()
+	b, err := afero.ReadFile(e.Fs, e.Paths.Bin(name))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(b))
+}
+
+func versionsOf(t *testing.T, i *installer.Installer) (versions []string, active, pinned string) {
+	t.Helper()
+	installed, err := i.Versions("go")
+	require.NoError(t, err)
+	for _, v := range installed {
+		versions = append(versions, v.Version)
+		if v.Active {
+			active = v.Version
+		}
+		if v.Pinned {
+			pinned = v.Version
+		}
+		assert.NotNil(t, v.Receipt)
+	}
+	return versions, active, pinned
+}
+
+func TestVersions_Use(t *testing.T) {
+	i, e := newInstaller(t)
+	install(t, i, e, map[string][]string{"1.9": {"go", "gofmt"}, "1.10": {"go"}}, "1.9", "1.10")
+
+	versions, active, _ := versionsOf(t, i)
+	assert.Equal(t, []string{"1.9", "1.10"}, versions)
+	assert.Equal(t, "1.10", active)
+	assertBin(t, e, "go", "go1.10")
+	exists, err := afero.Exists(e.Fs, e.Paths.Bin("gofmt"))
+	require.NoError(t, err)
+	assert.False(t, exists, "executables not provided by the active version are unlinked")
+
+	require.NoError(t, i.Use("go", "1.9"))
+	current, err := i.Current("go")
+	require.NoError(t, err)
+	assert.Equal(t, "1.9", current)
+	assertBin(t, e, "go", "go1.9")
+	assertBin(t, e, "gofmt", "gofmt1.9")
+	assert.Equal(t, "1.9", readReceipt(t, e).Version)
+
+	err = i.Use("go", "1.11")
+	assert.True(t, errors.Is(err, installer.ErrNotInstalled), "got %v", err)
+	assert.Error(t, i.Use("go", "current"))
+}
+
+func TestVersions_Pin(t *testing.T) {
+	i, e := newInstaller(t)
+	bins := map[string][]string{"1.16": {"go"}, "1.17": {"go"}, "1.18": {"go"}}
+	install(t, i, e, bins, "1.16", "1.17")
+
+	require.NoError(t, i.Pin("go", "1.16"))
+	_, active, pinned := versionsOf(t, i)
+	assert.Equal(t, "1.16", active)
+	assert.Equal(t, "1.16", pinned)
+
+	err := i.Use("go", "1.17")
+	assert.True(t, errors.Is(err, installer.ErrPinned), "got %v", err)
+	install(t, i, e, bins, "1.18")
+	versions, active, _ := versionsOf(t, i)
+	assert.Equal(t, []string{"1.16", "1.17", "1.18"}, versions)
+	assert.Equal(t, "1.16", active, "installing does not switch a pinned plugin")
+	assertBin(t, e, "go", "go1.16")
+
+	require.NoError(t, i.Unpin("go"))
+	require.NoError(t, i.Use("go", "1.18"))
+	_, active, pinned = versionsOf(t, i)
+	assert.Equal(t, "1.18", active)
+	assert.Empty(t, pinned)
+
+	require.NoError(t, i.Pin("go", ""))
+	assert.True(t, readReceipt(t, e).Pinned)
+}
+
+func TestVersions_UseRollback(t *testing.T) {
+	i, e := newInstaller(t)
+	install(t, i, e, map[string][]string{"1.16": {"go", "gofmt"}, "1.17": {"go"}}, "1.16", "1.17")
+	require.NoError(t, afero.WriteFile(e.Fs, e.Paths.Bin("gofmt"), []byte("other"), 0755))
+
+	assert.Error(t, i.Use("go", "1.16"))
+	current, err := i.Current("go")
+	require.NoError(t, err)
+	assert.Equal(t, "1.17", current)
+	assertBin(t, e, "go", "go1.17")
+	assertBin(t, e, "gofmt", "other")
+	assert.Equal(t, "1.17", readReceipt(t, e).Version)
+}
+
+func TestWhich(t *testing.T) {
+	i, e := newInstaller(t)
+	install(t, i, e, map[string][]string{"1.16": {"go", "gofmt"}, "1.17": {"go", "gofmt"}}, "1.16", "1.17")
+	require.NoError(t, afero.WriteFile(e.Fs, e.Paths.Bin("other"), []byte("other"), 0755))
+
+	link, err := i.Which("go")
+	require.NoError(t, err)
+	assert.Equal(t, &installer.Link{
+		Name:    "go",
+		Path:    e.Paths.Bin("go"),
+		Target:  filepath.Join(e.Paths.PluginCurrentPath("go"), "bin", "go"),
+		Plugin:  "go",
+		Version: "1.17",
+	}, link)
+
+	require.NoError(t, i.Use("go", "1.16"))
+	links, err := i.Links()
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, "go", links[0].Name)
+	assert.Equal(t, "1.16", links[0].Version)
+	assert.Equal(t, "gofmt", links[1].Name)
+	assert.Equal(t, "1.16", links[1].Version)
+
+	_, err = i.Which("other")
+	assert.Error(t, err)
+	_, err = i.Which("missing")
+	assert.True(t, errors.Is(err, installer.ErrNotInstalled), "got %v", err)
+}
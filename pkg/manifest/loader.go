@@ -0,0 +1,113 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+)
+
+// ErrNotFound is returned if an index does not contain the manifest of a plugin
+var ErrNotFound = errors.New("manifest not found")
+
+// Loader reads the manifests of the index repositories in Paths.IndexBase
+type Loader struct {
+	fs    afero.Fs
+	paths env.Paths
+}
+
+// NewLoader returns a Loader reading the indexes below paths using fs
+func NewLoader(fs afero.Fs, paths env.Paths) *Loader {
+	return &Loader{fs: fs, paths: paths}
+}
+
+// Load returns the manifest of plugin in index
+func (l *Loader) Load(index, plugin string) (*Manifest, error) {
+	if !nameRe.MatchString(plugin) {
+		return nil, errors.Errorf("invalid plugin name %q", plugin)
+	}
+	path := l.paths.IndexPluginManifestPath(index, plugin)
+	b, err := afero.ReadFile(l.fs, path)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(ErrNotFound, "plugin %s in index %s", plugin, index)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	m, err := Parse(b, path)
+	if err != nil {
+		return nil, err
+	}
+	m.Index = index
+	return m, nil
+}
+
+// LoadIndex returns the valid manifests of index sorted by name. Invalid manifests are
+// skipped and reported as an aggregated error.
+func (l *Loader) LoadIndex(index string) ([]*Manifest, error) {
+	dir := l.paths.IndexPluginsPath(index)
+	infos, err := afero.ReadDir(l.fs, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the plugins of index %s", index)
+	}
+
+	var manifests []*Manifest
+	var errs []error
+	for _, fi := range infos {
+		name := fi.Name()
+		if fi.IsDir() || filepath.Ext(name) != constants.ManifestExtension || strings.HasPrefix(name, ".") {
+			continue
+		}
+		m, err := l.Load(index, strings.TrimSuffix(name, constants.ManifestExtension))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Name < manifests[j].Name })
+	return manifests, utilerrors.NewAggregate(errs)
+}
+
+// Indexes returns the sorted names of the indexes in Paths.IndexBase
+func (l *Loader) Indexes() ([]string, error) {
+	infos, err := afero.ReadDir(l.fs, l.paths.IndexBase())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", l.paths.IndexBase())
+	}
+	var indexes []string
+	for _, fi := range infos {
+		if fi.IsDir() && !strings.HasPrefix(fi.Name(), ".") {
+			indexes = append(indexes, fi.Name())
+		}
+	}
+	sort.Strings(indexes)
+	return indexes, nil
+}
+
+// LoadAll returns the valid manifests of all indexes sorted by index and name. Invalid
+// manifests are skipped and reported as an aggregated error.
+func (l *Loader) LoadAll() ([]*Manifest, error) {
+	indexes, err := l.Indexes()
+	if err != nil {
+		return nil, err
+	}
+	var manifests []*Manifest
+	var errs []error
+	for _, index := range indexes {
+		loaded, err := l.LoadIndex(index)
+		manifests = append(manifests, loaded...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return manifests, utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,91 @@
+// Package manifest parses and validates the plugin manifests of index repositories.
+package manifest
+
+import (
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+const (
+	// APIVersion is the supported version of the manifest schema
+	APIVersion = "devctl.alexheld.io/v1alpha1"
+	// Kind is the kind of plugin manifests
+	Kind = "Plugin"
+)
+
+// Manifest describes the versions of a plugin and how to install them
+type Manifest struct {
+	APIVersion  string    `yaml:"apiVersion"`
+	Kind        string    `yaml:"kind"`
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description,omitempty"`
+	Homepage    string    `yaml:"homepage,omitempty"`
+	Versions    []Version `yaml:"versions"`
+	// Caveats are printed after the installation
+	Caveats string `yaml:"caveats,omitempty"`
+
+	// Index and Path are the index and the file the manifest was loaded from
+	Index string `yaml:"-"`
+	Path  string `yaml:"-"`
+}
+
+// Version is an installable version of a plugin
+type Version struct {
+	Version   string     `yaml:"version"`
+	Artifacts []Artifact `yaml:"artifacts"`
+}
+
+// Artifact is the archive or binary of a version for a platform
+type Artifact struct {
+	Platform Platform `yaml:"platform"`
+	URI      string   `yaml:"uri"`
+	SHA256   string   `yaml:"sha256"`
+	// Bin are the executables linked into Paths.BinPath
+	Bin []Bin `yaml:"bin,omitempty"`
+}
+
+// Platform is an operating system and architecture, e.g. linux/amd64
+type Platform struct {
+	OS   string `yaml:"os"`
+	Arch string `yaml:"arch"`
+}
+
+// Bin is an executable of an artifact
+type Bin struct {
+	// Name is the name of the link in Paths.BinPath
+	Name string `yaml:"name"`
+	// Path is the path of the executable relative to the extracted artifact
+	Path string `yaml:"path"`
+}
+
+// Version returns the version v
+func (m *Manifest) Version(v string) (*Version, bool) {
+	for i := range m.Versions {
+		if m.Versions[i].Version == v {
+			return &m.Versions[i], true
+		}
+	}
+	return nil, false
+}
+
+// Latest returns the highest version, see CompareVersions
+func (m *Manifest) Latest() (*Version, bool) {
+	var latest *Version
+	for i := range m.Versions {
+		if latest == nil || CompareVersions(m.Versions[i].Version, latest.Version) > 0 {
+			latest = &m.Versions[i]
+		}
+	}
+	return latest, latest != nil
+}
+
+// Artifact returns the artifact for the platform
+func (v *Version) Artifact(platform system.RuntimeInfo) (*Artifact, bool) {
+	for i := range v.Artifacts {
+		if p := v.Artifacts[i].Platform; p.OS == platform.OS && p.Arch == platform.Arch {
+			return &v.Artifacts[i], true
+		}
+	}
+	return nil, false
+}
+
+func (p Platform) String() string { return p.OS + "/" + p.Arch }
@@ -0,0 +1,204 @@
+package manifest_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/manifest"
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+const goManifest = `apiVersion: devctl.alexheld.io/v1alpha1
+kind: Plugin
+name: go
+homepage: https://golang.org
+versions:
+  - version: 1.16.8
+    artifacts:
+      - platform: {os: linux, arch: amd64}
+        uri: https://golang.org/dl/go1.16.8.linux-amd64.tar.gz
+        sha256: f32501aeb8b7b723bc7215f6c373abb6981bbc7e1c7b44e9f07317e1a300dce2
+        bin:
+          - {name: go, path: go/bin/go}
+  - version: 1.17.2
+    artifacts:
+      - platform: {os: linux, arch: amd64}
+        uri: https://golang.org/dl/go1.17.2.linux-amd64.tar.gz
+        sha256: f242a9db6a0ad1846de7b6d94d507915d14062660616a61ef7c808a76e4f1676
+        bin:
+          - {name: go, path: go/bin/go}
+          - {name: gofmt, path: go/bin/gofmt}
+      - platform: {os: darwin, arch: arm64}
+        uri: https://golang.org/dl/go1.17.2.darwin-arm64.tar.gz
+        sha256: ce8771bd3edfb5b28104084b56bbb532eeb47fbb7769c3e664c6223712c30904
+caveats: add $(devctl bin) to your PATH
+`
+
+func TestParse(t *testing.T) {
+	m, err := manifest.Parse([]byte(goManifest), "/index/default/plugins/go.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "go", m.Name)
+	assert.Equal(t, "/index/default/plugins/go.yaml", m.Path)
+	latest, ok := m.Latest()
+	require.True(t, ok)
+	assert.Equal(t, "1.17.2", latest.Version)
+
+	a, ok := latest.Artifact(system.RuntimeInfo{OS: "darwin", Arch: "arm64"})
+	require.True(t, ok)
+	assert.Equal(t, "https://golang.org/dl/go1.17.2.darwin-arm64.tar.gz", a.URI)
+	_, ok = latest.Artifact(system.RuntimeInfo{OS: "windows", Arch: "amd64"})
+	assert.False(t, ok)
+
+	v, ok := m.Version("1.16.8")
+	require.True(t, ok)
+	assert.Equal(t, []manifest.Bin{{Name: "go", Path: "go/bin/go"}}, v.Artifacts[0].Bin)
+}
+
+// versionManifest returns a manifest of plugin go with a single version
+func versionManifest(version string) string {
+	return `apiVersion: devctl.alexheld.io/v1alpha1
+kind: Plugin
+name: go
+versions:
+  - version: ` + version + `
+    artifacts:
+      - platform: {os: linux, arch: amd64}
+        uri: https://example.com/go.tar.gz
+        sha256: f242a9db6a0ad1846de7b6d94d507915d14062660616a61ef7c808a76e4f1676
+`
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		errors  []string
+	}{
+		"syntax": {
+			content: "name: go\n  versions: []\n",
+			errors:  []string{"go.yaml:2: mapping values are not allowed in this context"},
+		},
+		"unknown field": {
+			content: "apiVersion: devctl.alexheld.io/v1alpha1\nkind: Plugin\nname: go\nversion: 1.17\n",
+			errors:  []string{"go.yaml:4: field version not found in type manifest.Manifest"},
+		},
+		"not a mapping": {
+			content: "- go\n",
+			errors:  []string{"go.yaml:1:1: expected a mapping"},
+		},
+		"leading dot version": {
+			content: versionManifest(".1"),
+			errors:  []string{`go.yaml:5:14: versions[0].version: invalid version ".1"`},
+		},
+		"reserved version": {
+			content: versionManifest("current"),
+			errors:  []string{`go.yaml:5:14: versions[0].version: version "current" is reserved for the link to the active version`},
+		},
+		"invalid fields": {
+			content: `apiVersion: devctl.alexheld.io/v1alpha1
+kind: Plugin
+name: golang
+versions:
+  - version: 1.17.2
+    artifacts:
+      - platform: {os: linux}
+        uri: ftp://example.com/go.tar.gz
+        sha256: nope
+        bin:
+          - {name: go, path: ../go}
+      - platform: {os: linux}
+        uri: https://example.com/go.tar.gz
+        sha256: f242a9db6a0ad1846de7b6d94d507915d14062660616a61ef7c808a76e4f1676
+  - version: 1.17.2
+    artifacts: []
+`,
+			errors: []string{
+				`go.yaml:3:7: name: plugin name "golang" does not match the file name`,
+				`go.yaml:7:19: versions[0].artifacts[0].platform.arch: arch is required`,
+				`go.yaml:8:14: versions[0].artifacts[0].uri: unsupported scheme "ftp", expected https, http or file`,
+				`go.yaml:9:17: versions[0].artifacts[0].sha256: "nope" is not a hex encoded sha256`,
+				`go.yaml:11:30: versions[0].artifacts[0].bin[0].path: "../go" must be a clean path relative to the artifact`,
+				`go.yaml:12:19: versions[0].artifacts[1].platform.arch: arch is required`,
+				`go.yaml:12:19: versions[0].artifacts[1].platform: duplicate platform linux/`,
+				`go.yaml:15:14: versions[1].version: duplicate version "1.17.2"`,
+				`go.yaml:16:16: versions[1].artifacts: at least one artifact is required`,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := manifest.Parse([]byte(tt.content), "go.yaml")
+			require.Error(t, err)
+
+			var verr *manifest.ValidationError
+			require.True(t, errors.As(err, &verr), err.Error())
+			var got []string
+			for _, fe := range verr.Errors {
+				got = append(got, "go.yaml:"+fe.Error())
+			}
+			assert.Equal(t, tt.errors, got)
+		})
+	}
+}
+
+func TestLoader(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	paths := env.NewPaths("/sandbox")
+	write := func(index, name, content string) {
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(paths.IndexPluginsPath(index), name), []byte(content), 0644))
+	}
+	write("default", "go.yaml", goManifest)
+	write("default", "README.md", "# plugins")
+	write("work", "go.yaml", goManifest)
+	write("work", "broken.yaml", "name: [")
+
+	l := manifest.NewLoader(fs, paths)
+	m, err := l.Load("work", "go")
+	require.NoError(t, err)
+	assert.Equal(t, "work", m.Index)
+	assert.Equal(t, paths.IndexPluginManifestPath("work", "go"), m.Path)
+
+	_, err = l.Load("default", "node")
+	assert.True(t, errors.Is(err, manifest.ErrNotFound))
+
+	manifests, err := l.LoadIndex("default")
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+
+	indexes, err := l.Indexes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "work"}, indexes)
+
+	manifests, err = l.LoadAll()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken.yaml:1:")
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "default", manifests[0].Index)
+	assert.Equal(t, "work", manifests[1].Index)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.17.2", "1.17.2", 0},
+		{"1.17.10", "1.17.2", 1},
+		{"v16.0.0", "16", 0},
+		{"2.0.0-rc.1", "2.0.0", -1},
+		{"2.0.0-rc.2", "2.0.0-rc.10", -1},
+		{"2.0.0-alpha", "2.0.0-1", 1},
+		{"1.0.0+build.1", "1.0.0", 0},
+		{"1.9", "1.10", -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, manifest.CompareVersions(tt.a, tt.b), "%s <=> %s", tt.a, tt.b)
+		assert.Equal(t, -tt.want, manifest.CompareVersions(tt.b, tt.a), "%s <=> %s", tt.b, tt.a)
+	}
+}
@@ -0,0 +1,263 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+)
+
+var (
+	nameRe     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	checksumRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	lineRe     = regexp.MustCompile(`^line (\d+): (.*)$`)
+)
+
+// FieldError is an invalid field of a manifest and its location in the file
+type FieldError struct {
+	// Field is the path of the field, e.g. versions[0].artifacts[1].sha256
+	Field   string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e FieldError) Error() string {
+	// the errors of the yaml.v3 decoder have no column
+	location := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.Column == 0 {
+		location = strconv.Itoa(e.Line)
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", location, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, e.Field, e.Message)
+}
+
+// ValidationError lists the invalid fields of the manifest at Path
+type ValidationError struct {
+	Path   string
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		lines = append(lines, e.Path+":"+fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Schema validates serialized manifests, it implements validation.Schema
+type Schema struct{}
+
+// ValidateBytes parses and validates data
+func (Schema) ValidateBytes(data []byte) error {
+	_, err := Parse(data, "")
+	return err
+}
+
+// Parse decodes and validates the manifest read from path. Unknown fields and invalid
+// values are reported as *ValidationError with the line and column of the field.
+// If path is not empty, the name of the plugin must match the name of the file.
+func Parse(data []byte, path string) (*Manifest, error) {
+	v := &validator{path: path}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		v.fromYAMLError(err)
+		return nil, v.err()
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		v.root = doc
+		v.errorf(nil, "expected a mapping")
+		return nil, v.err()
+	}
+	v.root = doc.Content[0]
+
+	m := &Manifest{Path: path}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(m); err != nil {
+		v.fromYAMLError(err)
+		return nil, v.err()
+	}
+
+	v.validate(m)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type validator struct {
+	path   string
+	root   *yaml.Node
+	errors []FieldError
+}
+
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Path: v.path, Errors: v.errors}
+}
+
+// errorf reports an error at the node of field, or its closest existing parent
+func (v *validator) errorf(field []interface{}, format string, args ...interface{}) {
+	node := find(v.root, field...)
+	v.errors = append(v.errors, FieldError{
+		Field:   fieldName(field),
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// fromYAMLError converts the "line N: message" errors of yaml.v3
+func (v *validator) fromYAMLError(err error) {
+	var messages []string
+	if te, ok := err.(*yaml.TypeError); ok {
+		messages = te.Errors
+	} else {
+		messages = []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	for _, msg := range messages {
+		fe := FieldError{Message: msg}
+		if m := lineRe.FindStringSubmatch(msg); m != nil {
+			fe.Line, _ = strconv.Atoi(m[1])
+			fe.Message = m[2]
+		}
+		v.errors = append(v.errors, fe)
+	}
+}
+
+func (v *validator) validate(m *Manifest) {
+	if m.APIVersion != APIVersion {
+		v.errorf(f("apiVersion"), "unsupported apiVersion %q, expected %s", m.APIVersion, APIVersion)
+	}
+	if m.Kind != Kind {
+		v.errorf(f("kind"), "unsupported kind %q, expected %s", m.Kind, Kind)
+	}
+	if !nameRe.MatchString(m.Name) {
+		v.errorf(f("name"), "invalid plugin name %q", m.Name)
+	} else if v.path != "" && m.Name != strings.TrimSuffix(filepath.Base(v.path), constants.ManifestExtension) {
+		v.errorf(f("name"), "plugin name %q does not match the file name", m.Name)
+	}
+	if len(m.Versions) == 0 {
+		v.errorf(f("versions"), "at least one version is required")
+	}
+
+	versions := map[string]bool{}
+	for i, version := range m.Versions {
+		switch {
+		case version.Version == "":
+			v.errorf(f("versions", i, "version"), "version is required")
+		case strings.ContainsAny(version.Version, `/\ `) || strings.HasPrefix(version.Version, "."):
+			// a leading dot hides the install directory, like the staging directories
+			v.errorf(f("versions", i, "version"), "invalid version %q", version.Version)
+		case version.Version == constants.CurrentLink:
+			v.errorf(f("versions", i, "version"), "version %q is reserved for the link to the active version", version.Version)
+		case versions[version.Version]:
+			v.errorf(f("versions", i, "version"), "duplicate version %q", version.Version)
+		}
+		versions[version.Version] = true
+
+		if len(version.Artifacts) == 0 {
+			v.errorf(f("versions", i, "artifacts"), "at least one artifact is required")
+		}
+		platforms := map[Platform]bool{}
+		for j, a := range version.Artifacts {
+			v.validateArtifact(f("versions", i, "artifacts", j), a)
+			if platforms[a.Platform] {
+				v.errorf(f("versions", i, "artifacts", j, "platform"), "duplicate platform %s", a.Platform)
+			}
+			platforms[a.Platform] = true
+		}
+	}
+}
+
+func (v *validator) validateArtifact(field []interface{}, a Artifact) {
+	at := func(names ...interface{}) []interface{} { return append(append([]interface{}{}, field...), names...) }
+
+	if a.Platform.OS == "" {
+		v.errorf(at("platform", "os"), "os is required")
+	}
+	if a.Platform.Arch == "" {
+		v.errorf(at("platform", "arch"), "arch is required")
+	}
+	if u, err := url.Parse(a.URI); err != nil || a.URI == "" {
+		v.errorf(at("uri"), "invalid uri %q", a.URI)
+	} else if u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "file" {
+		v.errorf(at("uri"), "unsupported scheme %q, expected https, http or file", u.Scheme)
+	}
+	if !checksumRe.MatchString(a.SHA256) {
+		v.errorf(at("sha256"), "%q is not a hex encoded sha256", a.SHA256)
+	}
+
+	names := map[string]bool{}
+	for k, bin := range a.Bin {
+		switch {
+		case !nameRe.MatchString(bin.Name):
+			v.errorf(at("bin", k, "name"), "invalid executable name %q", bin.Name)
+		case names[bin.Name]:
+			v.errorf(at("bin", k, "name"), "duplicate executable %q", bin.Name)
+		}
+		names[bin.Name] = true
+		if bin.Path == "" || path.IsAbs(bin.Path) || path.Clean(bin.Path) != bin.Path || bin.Path == ".." || strings.HasPrefix(bin.Path, "../") {
+			v.errorf(at("bin", k, "path"), "%q must be a clean path relative to the artifact", bin.Path)
+		}
+	}
+}
+
+func f(field ...interface{}) []interface{} { return field }
+
+func fieldName(field []interface{}) string {
+	sb := &strings.Builder{}
+	for _, elem := range field {
+		switch e := elem.(type) {
+		case int:
+			fmt.Fprintf(sb, "[%d]", e)
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(e)
+		}
+	}
+	return sb.String()
+}
+
+// find returns the node of field below node, or the closest existing parent
+func find(node *yaml.Node, field ...interface{}) *yaml.Node {
+	for _, elem := range field {
+		var next *yaml.Node
+		switch e := elem.(type) {
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == e {
+						next = node.Content[i+1]
+					}
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && e < len(node.Content) {
+				next = node.Content[e]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
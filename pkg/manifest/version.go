@@ -0,0 +1,78 @@
+package manifest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares versions like 1.17.2, v16.0.0 or 2.0.0-rc.1 and returns
+// -1, 0 or 1 if a is lower, equal or higher than b. Numeric segments are compared as
+// numbers, a pre-release is lower than its release.
+func CompareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	aRelease, aPre := splitPrerelease(a)
+	bRelease, bPre := splitPrerelease(b)
+
+	if c := compareSegments(strings.Split(aRelease, "."), strings.Split(bRelease, ".")); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareSegments(strings.Split(aPre, "."), strings.Split(bPre, "."))
+}
+
+func splitPrerelease(v string) (release, pre string) {
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		if v[i] == '+' {
+			// build metadata is ignored
+			return v[:i], ""
+		}
+		pre = v[i+1:]
+		if j := strings.IndexByte(pre, '+'); j >= 0 {
+			pre = pre[:j]
+		}
+		return v[:i], pre
+	}
+	return v, ""
+}
+
+// compareSegments compares numeric segments as numbers and all others lexically,
+// missing segments are zero
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := "0", "0"
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		xn, xerr := strconv.ParseUint(x, 10, 64)
+		yn, yerr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case xerr == nil:
+			// numeric segments are lower than alphanumeric ones
+			return -1
+		case yerr == nil:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+// Package receipt records what was installed for a plugin, so that it can be listed,
+// verified, upgraded and uninstalled.
+package receipt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/alex-held/devctl-kit/pkg/system"
+)
+
+const (
+	// APIVersion is the version of the receipt schema written by Store.Write
+	APIVersion = "devctl.alexheld.io/v1alpha1"
+	// Kind is the kind of receipt documents
+	Kind = "Receipt"
+)
+
+// Receipt is the record of an installed plugin version
+type Receipt struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+
+	Plugin   string   `yaml:"plugin"`
+	Version  string   `yaml:"version"`
+	Source   Source   `yaml:"source"`
+	Platform Platform `yaml:"platform"`
+	// Checksum is the sha256 of the downloaded artifact
+	Checksum string `yaml:"checksum,omitempty"`
+	// Files are the installed files relative to the install directory of the version
+	Files []File `yaml:"files"`
+	// Bin are the executables of the version linked into Paths.BinPath when it is active
+	Bin         []Bin     `yaml:"bin,omitempty"`
+	InstalledAt time.Time `yaml:"installedAt"`
+	// Pinned is set on the receipt of the active version if it must not be switched
+	Pinned bool `yaml:"pinned,omitempty"`
+}
+
+// Source is the index the plugin was installed from
+type Source struct {
+	Index string `yaml:"index"`
+	URI   string `yaml:"uri,omitempty"`
+}
+
+// Platform is the platform the plugin was installed for
+type Platform struct {
+	OS   string `yaml:"os"`
+	Arch string `yaml:"arch"`
+}
+
+// File is an installed file and its sha256 checksum
+type File struct {
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// Bin is a link in Paths.BinPath to an executable of the version
+type Bin struct {
+	Name string `yaml:"name"`
+	// Path is the path of the executable relative to the install directory of the version
+	Path string `yaml:"path"`
+}
+
+// New returns a receipt of version of plugin installed now for platform
+func New(plugin, version string, platform system.RuntimeInfo) *Receipt {
+	return &Receipt{
+		APIVersion:  APIVersion,
+		Kind:        Kind,
+		Plugin:      plugin,
+		Version:     version,
+		Platform:    Platform{OS: platform.OS, Arch: platform.Arch},
+		InstalledAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// RuntimeInfo returns the platform as system.RuntimeInfo
+func (p Platform) RuntimeInfo() system.RuntimeInfo {
+	return system.RuntimeInfo{OS: p.OS, Arch: p.Arch}
+}
+
+// Checksum returns the hex encoded sha256 of the file at path
+func Checksum(fs afero.Fs, path string) (string, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,167 @@
+package receipt_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alex-held/devctl-kit/pkg/env/envtest"
+	"github.com/alex-held/devctl-kit/pkg/receipt"
+)
+
+const sum = "b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"
+
+func newReceipt(plugin string) *receipt.Receipt {
+	r := receipt.New(plugin, "1.17.2", envtest.DefaultRuntimeInfo)
+	r.InstalledAt = time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	r.Source = receipt.Source{Index: "default", URI: "https://github.com/alex-held/devctl-index.git"}
+	r.Checksum = sum
+	r.Files = []receipt.File{{Path: "bin/" + plugin, SHA256: sum}}
+	return r
+}
+
+func newStore(t *testing.T) (*receipt.Store, *envtest.Env) {
+	e := envtest.New(t)
+	s, err := receipt.NewStore(e.Factory)
+	require.NoError(t, err)
+	return s, e
+}
+
+func TestStore_WriteRead(t *testing.T) {
+	s, e := newStore(t)
+	r := newReceipt("go")
+	require.NoError(t, s.Write(r))
+
+	b, err := afero.ReadFile(e.Fs, e.Paths.PluginInstallReceiptPath("go"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: devctl.alexheld.io/v1alpha1
+kind: Receipt
+plugin: go
+version: 1.17.2
+source:
+    index: default
+    uri: https://github.com/alex-held/devctl-index.git
+platform:
+    os: linux
+    arch: amd64
+checksum: `+sum+`
+files:
+    - path: bin/go
+      sha256: `+sum+`
+installedAt: 2021-10-01T12:00:00Z
+`, string(b))
+
+	read, err := s.Read("go")
+	require.NoError(t, err)
+	assert.Equal(t, r, read)
+}
+
+func TestStore_ListDelete(t *testing.T) {
+	s, _ := newStore(t)
+	receipts, err := s.List()
+	require.NoError(t, err)
+	assert.Empty(t, receipts)
+
+	for _, plugin := range []string{"node", "go", "java"} {
+		require.NoError(t, s.Write(newReceipt(plugin)))
+	}
+	receipts, err = s.List()
+	require.NoError(t, err)
+	require.Len(t, receipts, 3)
+	assert.Equal(t, "go", receipts[0].Plugin)
+	assert.Equal(t, "node", receipts[2].Plugin)
+
+	require.NoError(t, s.Delete("java"))
+	assert.True(t, errors.Is(s.Delete("java"), receipt.ErrNotFound))
+	_, err = s.Read("java")
+	assert.True(t, errors.Is(err, receipt.ErrNotFound))
+
+	receipts, err = s.List()
+	require.NoError(t, err)
+	assert.Len(t, receipts, 2)
+}
+
+func TestStore_Versions(t *testing.T) {
+	s, e := newStore(t)
+	r := newReceipt("go")
+	r.Bin = []receipt.Bin{{Name: "go", Path: "bin/go"}}
+	require.NoError(t, s.WriteVersion(r))
+
+	exists, err := afero.Exists(e.Fs, e.Paths.PluginVersionInstallReceiptPath("go", "1.17.2"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+	read, err := s.ReadVersion("go", "1.17.2")
+	require.NoError(t, err)
+	assert.Equal(t, r, read)
+
+	receipts, err := s.List()
+	require.NoError(t, err)
+	assert.Empty(t, receipts, "version receipts are not listed")
+
+	require.NoError(t, s.DeleteVersion("go", "1.17.2"))
+	_, err = s.ReadVersion("go", "1.17.2")
+	assert.True(t, errors.Is(err, receipt.ErrNotFound))
+}
+
+func TestStore_Invalid(t *testing.T) {
+	s, e := newStore(t)
+
+	r := newReceipt("go")
+	r.Version = ""
+	r.Files = append(r.Files, receipt.File{Path: "../escape", SHA256: sum}, receipt.File{Path: "bin/go", SHA256: "nope"})
+	r.Bin = []receipt.Bin{{Name: "go", Path: "/usr/bin/go"}, {Name: "go", Path: "bin/go"}}
+	err := s.Write(r)
+	require.Error(t, err)
+	for _, msg := range []string{"version is required", `file "../escape" must be a clean path`, `duplicate file "bin/go"`, `path "/usr/bin/go" of bin go`, `duplicate bin "go"`} {
+		assert.Contains(t, err.Error(), msg)
+	}
+
+	_, err = s.Read("../go")
+	assert.Error(t, err)
+
+	require.NoError(t, afero.WriteFile(e.Fs, e.Paths.PluginInstallReceiptPath("node"), []byte("apiVersion: v0\nunknown: true\n"), 0644))
+	_, err = s.Read("node")
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "field unknown not found"), err.Error())
+}
+
+func TestStore_VersionTraversal(t *testing.T) {
+	s, e := newStore(t)
+	outside := e.Paths.PluginInstallReceiptPath("x")
+	require.NoError(t, afero.WriteFile(e.Fs, outside, []byte("keep"), 0644))
+
+	for _, version := range []string{"../x", "..", ".hidden", "a/b", `a\b`, "current"} {
+		t.Run(version, func(t *testing.T) {
+			_, err := s.ReadVersion("go", version)
+			assert.Error(t, err)
+			assert.False(t, errors.Is(err, receipt.ErrNotFound), "got %v", err)
+			assert.Error(t, s.DeleteVersion("go", version))
+
+			r := newReceipt("go")
+			r.Version = version
+			assert.Error(t, s.WriteVersion(r))
+			assert.Error(t, r.Validate())
+		})
+	}
+	b, err := afero.ReadFile(e.Fs, outside)
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(b), "the file outside of the receipts of the plugin is kept")
+}
+
+func TestSchema_ValidateBytes(t *testing.T) {
+	assert.Error(t, receipt.Schema{}.ValidateBytes([]byte("kind: Receipt\n")))
+	assert.Error(t, receipt.Schema{}.ValidateBytes([]byte("- not a receipt\n")))
+}
+
+func TestChecksum(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/file", []byte("foo\n"), 0644))
+	got, err := receipt.Checksum(fs, "/file")
+	require.NoError(t, err)
+	assert.Equal(t, sum, got)
+}
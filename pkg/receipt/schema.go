@@ -0,0 +1,118 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+)
+
+var (
+	nameRe     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	checksumRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// Schema validates serialized receipts, it implements validation.Schema
+type Schema struct{}
+
+// ValidateBytes decodes data strictly and validates the receipt
+func (Schema) ValidateBytes(data []byte) error {
+	r := &Receipt{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(r); err != nil {
+		return fmt.Errorf("invalid receipt: %v", err)
+	}
+	return r.Validate()
+}
+
+// ValidatePluginName checks that name can be used as a file name
+func ValidatePluginName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid plugin name %q", name)
+	}
+	return nil
+}
+
+// ValidateVersion checks that version can be used as a file name and is not the name
+// of the link to the active version
+func ValidateVersion(version string) error {
+	switch {
+	case version == "":
+		return fmt.Errorf("version is required")
+	case strings.ContainsAny(version, `/\ `) || strings.HasPrefix(version, "."):
+		return fmt.Errorf("invalid version %q", version)
+	case version == constants.CurrentLink:
+		return fmt.Errorf("version %q is reserved for the link to the active version", version)
+	}
+	return nil
+}
+
+// Validate checks the required fields, the checksums and that the files are relative
+// to the install directory
+func (r *Receipt) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if r.APIVersion != APIVersion {
+		add("unsupported apiVersion %q, expected %s", r.APIVersion, APIVersion)
+	}
+	if r.Kind != Kind {
+		add("unsupported kind %q, expected %s", r.Kind, Kind)
+	}
+	if err := ValidatePluginName(r.Plugin); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateVersion(r.Version); err != nil {
+		errs = append(errs, err)
+	}
+	if r.Source.Index == "" {
+		add("source.index is required")
+	}
+	if r.Platform.OS == "" || r.Platform.Arch == "" {
+		add("platform.os and platform.arch are required")
+	}
+	if r.Checksum != "" && !checksumRe.MatchString(r.Checksum) {
+		add("checksum %q is not a hex encoded sha256", r.Checksum)
+	}
+	if r.InstalledAt.IsZero() {
+		add("installedAt is required")
+	}
+	seen := map[string]bool{}
+	for _, f := range r.Files {
+		switch {
+		case !isRelative(f.Path):
+			add("file %q must be a clean path relative to the install directory", f.Path)
+		case seen[f.Path]:
+			add("duplicate file %q", f.Path)
+		case !checksumRe.MatchString(f.SHA256):
+			add("checksum %q of file %s is not a hex encoded sha256", f.SHA256, f.Path)
+		}
+		seen[f.Path] = true
+	}
+	bins := map[string]bool{}
+	for _, b := range r.Bin {
+		switch {
+		case !nameRe.MatchString(b.Name):
+			add("invalid bin name %q", b.Name)
+		case bins[b.Name]:
+			add("duplicate bin %q", b.Name)
+		case !isRelative(b.Path):
+			add("path %q of bin %s must be a clean path relative to the install directory", b.Path, b.Name)
+		}
+		bins[b.Name] = true
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func isRelative(p string) bool {
+	return p != "" && p != "." && p != ".." && !path.IsAbs(p) && path.Clean(p) == p && !strings.HasPrefix(p, "../")
+}
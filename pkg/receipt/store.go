@@ -0,0 +1,158 @@
+package receipt
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alex-held/devctl-kit/pkg/constants"
+	"github.com/alex-held/devctl-kit/pkg/env"
+	"github.com/alex-held/devctl-kit/pkg/validation"
+)
+
+// ErrNotFound is returned if a plugin has no receipt
+var ErrNotFound = errors.New("receipt not found")
+
+// Store reads and writes the receipts in Paths.InstallReceiptsPath
+type Store struct {
+	fs     afero.Fs
+	paths  env.Paths
+	schema validation.Schema
+}
+
+// NewStore returns a Store using the Fs and Paths of f. Receipts are validated by
+// Schema and the validator of f.
+func NewStore(f env.Factory) (*Store, error) {
+	validator, err := f.Validator(true)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		fs:     f.Fs(),
+		paths:  f.Paths(),
+		schema: validation.ConjunctiveSchema{Schema{}, validator},
+	}, nil
+}
+
+// Read returns the receipt of plugin or ErrNotFound. It is the receipt of the active
+// version if several versions are installed.
+func (s *Store) Read(plugin string) (*Receipt, error) {
+	if err := ValidatePluginName(plugin); err != nil {
+		return nil, err
+	}
+	return s.read(s.paths.PluginInstallReceiptPath(plugin), plugin, "")
+}
+
+// ReadVersion returns the receipt of version of plugin or ErrNotFound
+func (s *Store) ReadVersion(plugin, version string) (*Receipt, error) {
+	if err := ValidatePluginName(plugin); err != nil {
+		return nil, err
+	}
+	if err := ValidateVersion(version); err != nil {
+		return nil, err
+	}
+	return s.read(s.paths.PluginVersionInstallReceiptPath(plugin, version), plugin, version)
+}
+
+func (s *Store) read(path, plugin, version string) (*Receipt, error) {
+	b, err := afero.ReadFile(s.fs, path)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(ErrNotFound, "plugin %s", strings.TrimSpace(plugin+" "+version))
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	if err = s.schema.ValidateBytes(b); err != nil {
+		return nil, errors.Wrapf(err, "invalid receipt %s", path)
+	}
+	r := &Receipt{}
+	if err = yaml.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	if r.Plugin != plugin {
+		return nil, errors.Errorf("invalid receipt %s: plugin is %q", path, r.Plugin)
+	}
+	if version != "" && r.Version != version {
+		return nil, errors.Errorf("invalid receipt %s: version is %q", path, r.Version)
+	}
+	return r, nil
+}
+
+// Write validates and atomically writes the receipt, replacing a previous one
+func (s *Store) Write(r *Receipt) error {
+	return s.write(s.paths.PluginInstallReceiptPath(r.Plugin), r)
+}
+
+// WriteVersion validates and atomically writes the receipt of the version of r
+func (s *Store) WriteVersion(r *Receipt) error {
+	if err := ValidateVersion(r.Version); err != nil {
+		return err
+	}
+	return s.write(s.paths.PluginVersionInstallReceiptPath(r.Plugin, r.Version), r)
+}
+
+func (s *Store) write(path string, r *Receipt) error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err = s.schema.ValidateBytes(b); err != nil {
+		return errors.Wrapf(err, "invalid receipt of plugin %s", r.Plugin)
+	}
+	return env.WriteFileAtomic(s.fs, path, b, 0644)
+}
+
+// List returns the receipts sorted by plugin
+func (s *Store) List() ([]*Receipt, error) {
+	infos, err := afero.ReadDir(s.fs, s.paths.InstallReceiptsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", s.paths.InstallReceiptsPath())
+	}
+
+	var receipts []*Receipt
+	for _, fi := range infos {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != constants.ManifestExtension || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		r, err := s.Read(strings.TrimSuffix(fi.Name(), constants.ManifestExtension))
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, r)
+	}
+	sort.Slice(receipts, func(i, j int) bool { return receipts[i].Plugin < receipts[j].Plugin })
+	return receipts, nil
+}
+
+// Delete removes the receipt of plugin or returns ErrNotFound
+func (s *Store) Delete(plugin string) error {
+	if err := ValidatePluginName(plugin); err != nil {
+		return err
+	}
+	err := s.fs.Remove(s.paths.PluginInstallReceiptPath(plugin))
+	if os.IsNotExist(err) {
+		return errors.Wrapf(ErrNotFound, "plugin %s", plugin)
+	}
+	return err
+}
+
+// DeleteVersion removes the receipt of version of plugin or returns ErrNotFound
+func (s *Store) DeleteVersion(plugin, version string) error {
+	if err := ValidatePluginName(plugin); err != nil {
+		return err
+	}
+	if err := ValidateVersion(version); err != nil {
+		return err
+	}
+	err := s.fs.Remove(s.paths.PluginVersionInstallReceiptPath(plugin, version))
+	if os.IsNotExist(err) {
+		return errors.Wrapf(ErrNotFound, "plugin %s %s", plugin, version)
+	}
+	return err
+}